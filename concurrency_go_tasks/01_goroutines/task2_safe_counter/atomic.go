@@ -0,0 +1,36 @@
+package counter
+
+import "sync/atomic"
+
+// AtomicCounter — счётчик без блокировок на основе sync/atomic.
+// Нулевое значение готово к использованию. При очень высокой конкуренции
+// все ядра по-прежнему борются за одну кэш-линию; в этом случае
+// используйте StripedCounter.
+type AtomicCounter struct {
+	v atomic.Int64
+}
+
+// Inc увеличивает счётчик на 1.
+func (c *AtomicCounter) Inc() {
+	c.v.Add(1)
+}
+
+// Add прибавляет к счётчику delta.
+func (c *AtomicCounter) Add(delta int) {
+	c.v.Add(int64(delta))
+}
+
+// Value возвращает текущее значение счётчика.
+func (c *AtomicCounter) Value() int {
+	return int(c.v.Load())
+}
+
+// Reset обнуляет счётчик.
+func (c *AtomicCounter) Reset() {
+	c.v.Store(0)
+}
+
+// CompareAndSwap записывает new, только если текущее значение равно old.
+func (c *AtomicCounter) CompareAndSwap(old, new int) bool {
+	return c.v.CompareAndSwap(int64(old), int64(new))
+}
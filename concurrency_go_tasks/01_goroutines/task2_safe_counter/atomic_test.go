@@ -0,0 +1,48 @@
+package counter
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAtomicCounterZeroValue(t *testing.T) {
+	t.Parallel()
+	var c AtomicCounter
+	if v := c.Value(); v != 0 {
+		t.Fatalf("нулевой счётчик должен быть 0, получено %d", v)
+	}
+	c.Inc()
+	c.Add(41)
+	if v := c.Value(); v != 42 {
+		t.Fatalf("ожидалось 42, получено %d", v)
+	}
+}
+
+func TestAtomicCounterCompareAndSwap(t *testing.T) {
+	t.Parallel()
+	var c AtomicCounter
+	c.Add(7)
+	if c.CompareAndSwap(6, 0) {
+		t.Fatal("замена при несовпадающем значении не должна выполняться")
+	}
+	if !c.CompareAndSwap(7, 0) || c.Value() != 0 {
+		t.Fatalf("ожидалась замена 7 -> 0, значение %d", c.Value())
+	}
+
+	// Ровно одна из конкурирующих горутин должна выиграть замену.
+	var wg sync.WaitGroup
+	var winners AtomicCounter
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c.CompareAndSwap(0, 1) {
+				winners.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	if n := winners.Value(); n != 1 {
+		t.Errorf("ожидался ровно один успешный CAS, получено %d", n)
+	}
+}
@@ -2,6 +2,29 @@ package counter
 
 import "sync"
 
+// Interface — общий набор операций для всех реализаций счётчика.
+// Реализации отличаются поведением под конкуренцией, но не семантикой.
+// CompareAndSwap сюда не входит, потому что StripedCounter не может
+// выполнить его атомарно; он вынесен в Swapper.
+type Interface interface {
+	Inc()
+	Add(delta int)
+	Value() int
+	Reset()
+}
+
+// Swapper — счётчик, поддерживающий атомарное сравнение с заменой.
+type Swapper interface {
+	Interface
+	CompareAndSwap(old, new int) bool
+}
+
+var (
+	_ Swapper   = (*Counter)(nil)
+	_ Swapper   = (*AtomicCounter)(nil)
+	_ Interface = (*StripedCounter)(nil)
+)
+
 // Counter хранит целое значение и мьютекс для безопасного доступа.
 type Counter struct {
 	mu sync.Mutex
@@ -10,11 +33,38 @@ type Counter struct {
 
 // Inc увеличивает счётчик на 1 с защитой от гонок.
 func (c *Counter) Inc() {
-	// TODO: реализовать инкремент с использованием мьютекса
+	c.Add(1)
+}
+
+// Add прибавляет к счётчику delta, которое может быть отрицательным.
+func (c *Counter) Add(delta int) {
+	c.mu.Lock()
+	c.v += delta
+	c.mu.Unlock()
 }
 
 // Value возвращает текущее значение счётчика безопасно для гонок.
 func (c *Counter) Value() int {
-	// TODO: вернуть значение с учётом блокировки
-	return 0
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.v
+}
+
+// Reset обнуляет счётчик.
+func (c *Counter) Reset() {
+	c.mu.Lock()
+	c.v = 0
+	c.mu.Unlock()
+}
+
+// CompareAndSwap записывает new, только если текущее значение равно old,
+// и сообщает, произошла ли замена.
+func (c *Counter) CompareAndSwap(old, new int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.v != old {
+		return false
+	}
+	c.v = new
+	return true
 }
@@ -248,3 +248,112 @@ func BenchmarkCounterConcurrent(b *testing.B) {
 		}
 	})
 }
+
+// implementations перечисляет все реализации Interface для общих тестов
+// и бенчмарков.
+var implementations = []struct {
+	name string
+	new  func() Interface
+}{
+	{"Mutex", func() Interface { return &Counter{} }},
+	{"Atomic", func() Interface { return &AtomicCounter{} }},
+	{"Striped", func() Interface { return NewStriped() }},
+}
+
+func TestInterfaceImplementations(t *testing.T) {
+	t.Parallel()
+	for _, impl := range implementations {
+		impl := impl
+		t.Run(impl.name, func(t *testing.T) {
+			t.Parallel()
+			c := impl.new()
+			var wg sync.WaitGroup
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						c.Inc()
+						c.Add(3)
+						c.Add(-2)
+					}
+				}()
+			}
+			wg.Wait()
+			if v := c.Value(); v != 20000 {
+				t.Fatalf("ожидалось 20000, получено %d", v)
+			}
+			c.Reset()
+			if v := c.Value(); v != 0 {
+				t.Fatalf("после Reset ожидался 0, получено %d", v)
+			}
+		})
+	}
+}
+
+func TestCounterCompareAndSwap(t *testing.T) {
+	t.Parallel()
+	var c Counter
+	c.Add(5)
+	if c.CompareAndSwap(4, 10) {
+		t.Fatal("замена при несовпадающем значении не должна выполняться")
+	}
+	if !c.CompareAndSwap(5, 10) || c.Value() != 10 {
+		t.Fatalf("ожидалась замена 5 -> 10, значение %d", c.Value())
+	}
+
+	// Параллельные CAS-инкременты не должны терять обновления.
+	c.Reset()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for {
+					v := c.Value()
+					if c.CompareAndSwap(v, v+1) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if v := c.Value(); v != 5000 {
+		t.Errorf("ожидалось 5000, получено %d", v)
+	}
+}
+
+// BenchmarkContention сравнивает реализации при одновременных обновлениях
+// из всех горутин; -cpu позволяет варьировать число ядер.
+func BenchmarkContention(b *testing.B) {
+	for _, impl := range implementations {
+		b.Run(impl.name+"/Inc", func(b *testing.B) {
+			c := impl.new()
+			b.SetParallelism(16)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.Inc()
+				}
+			})
+		})
+		b.Run(impl.name+"/IncWithReads", func(b *testing.B) {
+			c := impl.new()
+			b.SetParallelism(16)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if i%100 == 0 {
+						c.Value()
+					} else {
+						c.Inc()
+					}
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package counter
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Replicated — состояние, которое можно передать на другой узел и
+// слить с тамошней копией. Слияние коммутативно, ассоциативно и
+// идемпотентно, поэтому порядок и повторы доставки не важны.
+type Replicated interface {
+	MarshalJSON() ([]byte, error)
+	MergeJSON(data []byte) error
+}
+
+var (
+	_ Replicated = (*GCounter)(nil)
+	_ Replicated = (*PNCounter)(nil)
+)
+
+// GCounter — только растущий счётчик (G-Counter CRDT). Каждый узел
+// увеличивает свою ячейку, а значение равно сумме ячеек всех узлов.
+type GCounter struct {
+	mu     sync.Mutex
+	node   string
+	counts map[string]uint64
+}
+
+// NewGCounter создаёт счётчик для узла node. Идентификатор узла должен
+// быть уникален среди всех реплик.
+func NewGCounter(node string) *GCounter {
+	return &GCounter{node: node, counts: make(map[string]uint64)}
+}
+
+// Inc увеличивает счётчик на 1.
+func (g *GCounter) Inc() {
+	g.Add(1)
+}
+
+// Add увеличивает ячейку текущего узла на delta.
+func (g *GCounter) Add(delta uint64) {
+	g.mu.Lock()
+	g.counts[g.node] += delta
+	g.mu.Unlock()
+}
+
+// Value возвращает сумму по всем известным узлам.
+func (g *GCounter) Value() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var sum uint64
+	for _, v := range g.counts {
+		sum += v
+	}
+	return sum
+}
+
+// Merge вливает состояние other, беря по каждому узлу максимум.
+func (g *GCounter) Merge(other *GCounter) {
+	if g == other {
+		return
+	}
+	state := other.state()
+	g.mu.Lock()
+	mergeMax(g.counts, state)
+	g.mu.Unlock()
+}
+
+func (g *GCounter) state() map[string]uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	state := make(map[string]uint64, len(g.counts))
+	for k, v := range g.counts {
+		state[k] = v
+	}
+	return state
+}
+
+// MarshalJSON сериализует ячейки всех узлов как объект {"узел": значение}.
+func (g *GCounter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.state())
+}
+
+// MergeJSON сливает состояние, полученное от MarshalJSON другой реплики.
+func (g *GCounter) MergeJSON(data []byte) error {
+	var state map[string]uint64
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	mergeMax(g.counts, state)
+	g.mu.Unlock()
+	return nil
+}
+
+func mergeMax(dst, src map[string]uint64) {
+	for k, v := range src {
+		if v > dst[k] {
+			dst[k] = v
+		}
+	}
+}
+
+// PNCounter — счётчик с увеличением и уменьшением (PN-Counter CRDT):
+// пара G-Counter для прибавлений и вычитаний.
+type PNCounter struct {
+	p, n *GCounter
+}
+
+// pnState — сериализованное состояние PNCounter.
+type pnState struct {
+	P map[string]uint64 `json:"p"`
+	N map[string]uint64 `json:"n"`
+}
+
+// NewPNCounter создаёт счётчик для узла node.
+func NewPNCounter(node string) *PNCounter {
+	return &PNCounter{p: NewGCounter(node), n: NewGCounter(node)}
+}
+
+// Inc увеличивает счётчик на 1.
+func (c *PNCounter) Inc() {
+	c.p.Add(1)
+}
+
+// Dec уменьшает счётчик на 1.
+func (c *PNCounter) Dec() {
+	c.n.Add(1)
+}
+
+// Add прибавляет delta, которое может быть отрицательным.
+func (c *PNCounter) Add(delta int) {
+	if delta >= 0 {
+		c.p.Add(uint64(delta))
+	} else {
+		c.n.Add(uint64(-delta))
+	}
+}
+
+// Value возвращает разность прибавлений и вычитаний по всем узлам.
+func (c *PNCounter) Value() int {
+	return int(c.p.Value()) - int(c.n.Value())
+}
+
+// Merge вливает состояние other.
+func (c *PNCounter) Merge(other *PNCounter) {
+	c.p.Merge(other.p)
+	c.n.Merge(other.n)
+}
+
+// MarshalJSON сериализует состояние как {"p": {...}, "n": {...}}.
+func (c *PNCounter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pnState{P: c.p.state(), N: c.n.state()})
+}
+
+// MergeJSON сливает состояние, полученное от MarshalJSON другой реплики.
+func (c *PNCounter) MergeJSON(data []byte) error {
+	var state pnState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	c.p.mu.Lock()
+	mergeMax(c.p.counts, state.P)
+	c.p.mu.Unlock()
+	c.n.mu.Lock()
+	mergeMax(c.n.counts, state.N)
+	c.n.mu.Unlock()
+	return nil
+}
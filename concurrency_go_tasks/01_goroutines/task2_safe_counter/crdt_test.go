@@ -0,0 +1,107 @@
+package counter
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGCounterMerge(t *testing.T) {
+	t.Parallel()
+	a, b, c := NewGCounter("a"), NewGCounter("b"), NewGCounter("c")
+	a.Add(3)
+	b.Add(5)
+	c.Inc()
+
+	// Слияние в разном порядке и с повторами даёт одинаковый результат.
+	a.Merge(b)
+	a.Merge(c)
+	a.Merge(b)
+	c.Merge(a)
+	b.Merge(c)
+	for name, g := range map[string]*GCounter{"a": a, "b": b, "c": c} {
+		if v := g.Value(); v != 9 {
+			t.Errorf("%s: ожидалось 9, получено %d", name, v)
+		}
+	}
+
+	a.Merge(a)
+	if v := a.Value(); v != 9 {
+		t.Errorf("слияние с самим собой не должно менять значение, получено %d", v)
+	}
+}
+
+func TestGCounterJSON(t *testing.T) {
+	t.Parallel()
+	a, b := NewGCounter("a"), NewGCounter("b")
+	a.Add(2)
+	b.Add(7)
+	data, err := a.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":2}` {
+		t.Errorf("неожиданная сериализация: %s", data)
+	}
+	if err := b.MergeJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.MergeJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if v := b.Value(); v != 9 {
+		t.Errorf("ожидалось 9, получено %d", v)
+	}
+	if err := b.MergeJSON([]byte("garbage")); err == nil {
+		t.Error("ожидалась ошибка разбора")
+	}
+}
+
+func TestPNCounter(t *testing.T) {
+	t.Parallel()
+	a, b := NewPNCounter("a"), NewPNCounter("b")
+	a.Add(10)
+	a.Dec()
+	b.Add(-4)
+	b.Inc()
+
+	data, err := b.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.MergeJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	b.Merge(a)
+	if va, vb := a.Value(), b.Value(); va != 6 || vb != 6 {
+		t.Errorf("ожидалось 6 на обеих репликах, получено %d и %d", va, vb)
+	}
+}
+
+func TestPNCounterConcurrent(t *testing.T) {
+	t.Parallel()
+	a, b := NewPNCounter("a"), NewPNCounter("b")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				a.Inc()
+				b.Merge(a)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Dec()
+				a.Merge(b)
+			}
+		}()
+	}
+	wg.Wait()
+	a.Merge(b)
+	b.Merge(a)
+	if va, vb := a.Value(), b.Value(); va != 0 || vb != 0 {
+		t.Errorf("после полного обмена ожидалось 0, получено %d и %d", va, vb)
+	}
+}
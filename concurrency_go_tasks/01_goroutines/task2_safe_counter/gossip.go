@@ -0,0 +1,172 @@
+package counter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+var ErrNotLoopback = errors.New("counter: gossip is limited to loopback addresses") // адрес не на интерфейсе loopback
+
+// maxGossipMessage ограничивает размер принимаемого состояния.
+const maxGossipMessage = 1 << 20
+
+// Gossip периодически рассылает состояние реплики соседям по TCP и
+// сливает полученные состояния. Предназначен для тестов и локальных
+// экспериментов, поэтому работает только с адресами loopback.
+type Gossip struct {
+	state Replicated
+	ln    net.Listener
+
+	mu    sync.Mutex
+	peers []string
+	errs  func(error)
+
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+	wg        sync.WaitGroup
+}
+
+// StartGossip начинает принимать состояния на addr (например,
+// "127.0.0.1:0") и рассылать state соседям каждые interval. Ошибки
+// обмена передаются в onErr, если он задан.
+func StartGossip(state Replicated, addr string, interval time.Duration, onErr func(error)) (*Gossip, error) {
+	if err := checkLoopback(addr); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	g := &Gossip{state: state, ln: ln, errs: onErr, done: make(chan struct{})}
+	g.wg.Add(2)
+	go g.accept()
+	go g.loop(interval)
+	return g, nil
+}
+
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("%w: %s", ErrNotLoopback, addr)
+	}
+	return nil
+}
+
+// Addr возвращает адрес, на котором принимаются состояния.
+func (g *Gossip) Addr() string {
+	return g.ln.Addr().String()
+}
+
+// AddPeer добавляет соседа, которому будет рассылаться состояние.
+func (g *Gossip) AddPeer(addr string) error {
+	if err := checkLoopback(addr); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.peers = append(g.peers, addr)
+	g.mu.Unlock()
+	return nil
+}
+
+// Sync немедленно отправляет состояние всем соседям.
+func (g *Gossip) Sync() error {
+	data, err := g.state.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	g.mu.Lock()
+	peers := append([]string(nil), g.peers...)
+	g.mu.Unlock()
+
+	var errs []error
+	for _, peer := range peers {
+		if err := push(peer, data); err != nil {
+			errs = append(errs, fmt.Errorf("counter: gossip to %s: %w", peer, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func push(addr string, data []byte) error {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(time.Now().Add(time.Second)); err != nil {
+		return err
+	}
+	_, err = conn.Write(data)
+	return err
+}
+
+func (g *Gossip) loop(interval time.Duration) {
+	defer g.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := g.Sync(); err != nil {
+				g.report(err)
+			}
+		case <-g.done:
+			return
+		}
+	}
+}
+
+func (g *Gossip) accept() {
+	defer g.wg.Done()
+	for {
+		conn, err := g.ln.Accept()
+		if err != nil {
+			return
+		}
+		g.receive(conn)
+	}
+}
+
+// receive читает одно состояние до закрытия соединения и сливает его.
+func (g *Gossip) receive(conn net.Conn) {
+	defer conn.Close()
+	if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		g.report(err)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(conn, maxGossipMessage))
+	if err == nil {
+		err = g.state.MergeJSON(data)
+	}
+	if err != nil {
+		g.report(fmt.Errorf("counter: gossip from %s: %w", conn.RemoteAddr(), err))
+	}
+}
+
+func (g *Gossip) report(err error) {
+	if g.errs != nil {
+		g.errs(err)
+	}
+}
+
+// Close останавливает рассылку и приём и дожидается фоновых горутин.
+// Повторный вызов безопасен и возвращает результат первого.
+func (g *Gossip) Close() error {
+	g.closeOnce.Do(func() {
+		close(g.done)
+		g.closeErr = g.ln.Close()
+		g.wg.Wait()
+	})
+	return g.closeErr
+}
@@ -0,0 +1,144 @@
+package counter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGossipConverges(t *testing.T) {
+	t.Parallel()
+	const replicas = 3
+	counters := make([]*PNCounter, replicas)
+	nodes := make([]*Gossip, replicas)
+	for i := range nodes {
+		counters[i] = NewPNCounter(string(rune('a' + i)))
+		g, err := StartGossip(counters[i], "127.0.0.1:0", 10*time.Millisecond, nil)
+		if err != nil {
+			t.Fatalf("StartGossip: %v", err)
+		}
+		defer g.Close()
+		nodes[i] = g
+	}
+	// Кольцо: каждый узел знает только следующего, но состояние всё равно
+	// доходит до всех через промежуточные слияния.
+	for i, g := range nodes {
+		if err := g.AddPeer(nodes[(i+1)%replicas].Addr()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counters[0].Add(5)
+	counters[1].Add(-2)
+	counters[2].Inc()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		converged := true
+		for _, c := range counters {
+			if c.Value() != 4 {
+				converged = false
+			}
+		}
+		if converged {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("реплики не сошлись: %d, %d, %d", counters[0].Value(), counters[1].Value(), counters[2].Value())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGossipSync(t *testing.T) {
+	t.Parallel()
+	a, b := NewGCounter("a"), NewGCounter("b")
+	ga, err := StartGossip(a, "127.0.0.1:0", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ga.Close()
+	gb, err := StartGossip(b, "localhost:0", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gb.Close()
+
+	a.Add(3)
+	if err := ga.AddPeer(gb.Addr()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ga.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Value() != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("состояние не доставлено, значение %d", b.Value())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestGossipLoopbackOnly(t *testing.T) {
+	t.Parallel()
+	if _, err := StartGossip(NewGCounter("a"), "0.0.0.0:0", time.Second, nil); !errors.Is(err, ErrNotLoopback) {
+		t.Errorf("ожидалась ErrNotLoopback для 0.0.0.0, получено %v", err)
+	}
+	g, err := StartGossip(NewGCounter("a"), "127.0.0.1:0", time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if err := g.AddPeer("192.0.2.1:9000"); !errors.Is(err, ErrNotLoopback) {
+		t.Errorf("ожидалась ErrNotLoopback для внешнего соседа, получено %v", err)
+	}
+	if err := g.AddPeer("[::1]:9000"); err != nil {
+		t.Errorf("IPv6 loopback должен приниматься: %v", err)
+	}
+}
+
+func TestGossipReportsErrors(t *testing.T) {
+	t.Parallel()
+	errs := make(chan error, 10)
+	g, err := StartGossip(NewGCounter("a"), "127.0.0.1:0", 5*time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	// Порт закрытого слушателя гарантированно не принимает соединения.
+	dead, err := StartGossip(NewGCounter("b"), "127.0.0.1:0", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := dead.Addr()
+	dead.Close()
+	if err := g.AddPeer(addr); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ошибка отправки недоступному соседу должна передаваться в onErr")
+	}
+}
+
+func TestGossipCloseTwice(t *testing.T) {
+	t.Parallel()
+	g, err := StartGossip(NewGCounter("a"), "127.0.0.1:0", time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := g.Close(); err != nil {
+		t.Errorf("повторный Close должен быть безопасен: %v", err)
+	}
+}
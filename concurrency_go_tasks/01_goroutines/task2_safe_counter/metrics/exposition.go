@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType — тип содержимого текстового формата Prometheus.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText записывает все метрики в текстовом формате Prometheus.
+// Метрики и ряды упорядочены по имени и меткам, поэтому вывод стабилен.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+	seriesByFamily := make([][]*series, len(families))
+	for i, f := range families {
+		ss := make([]*series, 0, len(f.series))
+		for _, s := range f.series {
+			ss = append(ss, s)
+		}
+		sort.Slice(ss, func(i, j int) bool { return ss[i].labels < ss[j].labels })
+		seriesByFamily[i] = ss
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for i, f := range families {
+		if f.help != "" {
+			bw.WriteString("# HELP " + f.name + " " + escapeHelp(f.help) + "\n")
+		}
+		bw.WriteString("# TYPE " + f.name + " " + f.kind.String() + "\n")
+		for _, s := range seriesByFamily[i] {
+			switch m := s.metric.(type) {
+			case *Counter:
+				writeSample(bw, f.name, s.labels, "", float64(m.Value()))
+			case *Gauge:
+				writeSample(bw, f.name, s.labels, "", m.Value())
+			case *Histogram:
+				writeHistogram(bw, f.name, s.labels, m)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func writeHistogram(w *bufio.Writer, name, labels string, h *Histogram) {
+	// Корзины выводятся накопительно: le="x" включает все наблюдения <= x.
+	var cumulative int
+	for i, upper := range h.upper {
+		cumulative += h.counts[i].Value()
+		writeSample(w, name+"_bucket", labels, `le="`+formatFloat(upper)+`"`, float64(cumulative))
+	}
+	cumulative += h.counts[len(h.upper)].Value()
+	writeSample(w, name+"_bucket", labels, `le="+Inf"`, float64(cumulative))
+	writeSample(w, name+"_sum", labels, "", h.Sum())
+	writeSample(w, name+"_count", labels, "", float64(cumulative))
+}
+
+func writeSample(w *bufio.Writer, name, labels, extra string, v float64) {
+	w.WriteString(name)
+	if labels != "" || extra != "" {
+		w.WriteByte('{')
+		w.WriteString(labels)
+		if labels != "" && extra != "" {
+			w.WriteByte(',')
+		}
+		w.WriteString(extra)
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(v string) string { return labelEscaper.Replace(v) }
+func escapeHelp(v string) string  { return helpEscaper.Replace(v) }
+
+// Handler возвращает http.Handler, отдающий метрики реестра.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := r.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.Counter("http_requests_total", "Total HTTP requests.", Labels{"method": "GET", "code": "200"}).Add(3)
+	r.Counter("http_requests_total", "Total HTTP requests.", Labels{"method": "POST", "code": "500"}).Inc()
+	r.Gauge("queue_depth", "Items waiting.\nSecond line.", nil).Set(2.5)
+	r.Gauge("label_escaping", "", Labels{"path": "C:\\dir \"x\"\n"}).Set(1)
+	h := r.Histogram("latency_seconds", "Request latency.", []float64{1, 0.1}, Labels{"op": "read"})
+	for _, v := range []float64{0.05, 0.1, 0.5, 3} {
+		h.Observe(v)
+	}
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	want := `# HELP http_requests_total Total HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{code="200",method="GET"} 3
+http_requests_total{code="500",method="POST"} 1
+# TYPE label_escaping gauge
+label_escaping{path="C:\\dir \"x\"\n"} 1
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{op="read",le="0.1"} 2
+latency_seconds_bucket{op="read",le="1"} 3
+latency_seconds_bucket{op="read",le="+Inf"} 4
+latency_seconds_sum{op="read"} 3.65
+latency_seconds_count{op="read"} 4
+# HELP queue_depth Items waiting.\nSecond line.
+# TYPE queue_depth gauge
+queue_depth 2.5
+`
+	if got := b.String(); got != want {
+		t.Errorf("неверный вывод:\n--- получено ---\n%s--- ожидалось ---\n%s", got, want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.Counter("up", "", nil).Inc()
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if ct := resp.Header.Get("Content-Type"); ct != ContentType {
+		t.Errorf("ожидался Content-Type %q, получено %q", ContentType, ct)
+	}
+	if string(body) != "# TYPE up counter\nup 1\n" {
+		t.Errorf("неверное тело ответа: %q", body)
+	}
+}
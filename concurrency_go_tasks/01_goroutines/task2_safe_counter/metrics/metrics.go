@@ -0,0 +1,259 @@
+// Package metrics реализует счётчики, измерители и гистограммы с метками
+// и их выдачу в текстовом формате Prometheus. Счётчики построены на
+// counter.Counter.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	counter "concurrency_go_tasks/01_goroutines/task2_safe_counter"
+)
+
+// Labels — набор меток, отличающий один ряд метрики от другого.
+type Labels map[string]string
+
+// DefBuckets — границы гистограммы по умолчанию, рассчитанные на
+// длительности в секундах.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	nameRE  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+type kind int
+
+const (
+	kindCounter kind = iota
+	kindGauge
+	kindHistogram
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindCounter:
+		return "counter"
+	case kindGauge:
+		return "gauge"
+	}
+	return "histogram"
+}
+
+// Registry хранит метрики по имени и набору меток. Повторный запрос той
+// же метрики с теми же метками возвращает уже созданный ряд.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// family — все ряды одной метрики.
+type family struct {
+	name    string
+	help    string
+	kind    kind
+	buckets []float64
+	series  map[string]*series
+}
+
+// series — один ряд с конкретными значениями меток.
+type series struct {
+	labels string // отсортированные метки в формате exposition
+	metric interface{}
+}
+
+// NewRegistry создаёт пустой реестр.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter возвращает монотонный счётчик name с метками labels, создавая
+// его при первом обращении. Паникует при некорректном имени или если под
+// этим именем уже зарегистрирована метрика другого типа.
+func (r *Registry) Counter(name, help string, labels Labels) *Counter {
+	return r.get(name, help, kindCounter, nil, labels, func() interface{} {
+		return &Counter{}
+	}).(*Counter)
+}
+
+// Gauge возвращает измеритель name с метками labels.
+func (r *Registry) Gauge(name, help string, labels Labels) *Gauge {
+	return r.get(name, help, kindGauge, nil, labels, func() interface{} {
+		return &Gauge{}
+	}).(*Gauge)
+}
+
+// Histogram возвращает гистограмму name с метками labels и верхними
+// границами корзин buckets (по умолчанию DefBuckets). Границы задаются
+// при первой регистрации имени и общие для всех рядов.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels Labels) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return r.get(name, help, kindHistogram, buckets, labels, nil).(*Histogram)
+}
+
+func (r *Registry) get(name, help string, k kind, buckets []float64, labels Labels, create func() interface{}) interface{} {
+	if !nameRE.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	key := labelKey(labels)
+
+	r.mu.RLock()
+	f, ok := r.families[name]
+	var s *series
+	if ok {
+		s = f.series[key]
+	}
+	r.mu.RUnlock()
+	if ok && f.kind != k {
+		panic(fmt.Sprintf("metrics: %s already registered as %s", name, f.kind))
+	}
+	if s != nil {
+		return s.metric
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok = r.families[name]
+	if !ok {
+		f = &family{name: name, help: help, kind: k, buckets: buckets, series: make(map[string]*series)}
+		r.families[name] = f
+	} else if f.kind != k {
+		panic(fmt.Sprintf("metrics: %s already registered as %s", name, f.kind))
+	}
+	if s, ok := f.series[key]; ok {
+		return s.metric
+	}
+	var m interface{}
+	if k == kindHistogram {
+		m = newHistogram(f.buckets)
+	} else {
+		m = create()
+	}
+	f.series[key] = &series{labels: key, metric: m}
+	return m
+}
+
+// labelKey строит каноническое представление меток: пары name="value",
+// отсортированные по имени.
+func labelKey(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if !labelRE.MatchString(name) || strings.HasPrefix(name, "__") {
+			panic(fmt.Sprintf("metrics: invalid label name %q", name))
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + `="` + escapeLabel(labels[name]) + `"`
+	}
+	return strings.Join(parts, ",")
+}
+
+// Counter — монотонно растущий счётчик.
+type Counter struct {
+	c counter.Counter
+}
+
+// Inc увеличивает счётчик на 1.
+func (c *Counter) Inc() {
+	c.c.Inc()
+}
+
+// Add увеличивает счётчик на delta. Отрицательное delta нарушает
+// монотонность и приводит к панике.
+func (c *Counter) Add(delta int) {
+	if delta < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.c.Add(delta)
+}
+
+// Value возвращает текущее значение счётчика.
+func (c *Counter) Value() int {
+	return c.c.Value()
+}
+
+// Gauge — значение, которое может как расти, так и уменьшаться.
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+// Set устанавливает значение.
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+// Add прибавляет delta к значению.
+func (g *Gauge) Add(delta float64) {
+	addFloat(&g.bits, delta)
+}
+
+// Inc увеличивает значение на 1.
+func (g *Gauge) Inc() {
+	g.Add(1)
+}
+
+// Dec уменьшает значение на 1.
+func (g *Gauge) Dec() {
+	g.Add(-1)
+}
+
+// Value возвращает текущее значение.
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+// Histogram распределяет наблюдения по корзинам и хранит их сумму.
+type Histogram struct {
+	upper  []float64
+	counts []counter.Counter // counts[len(upper)] — корзина +Inf
+	count  counter.Counter
+	sum    atomic.Uint64
+}
+
+func newHistogram(upper []float64) *Histogram {
+	return &Histogram{upper: upper, counts: make([]counter.Counter, len(upper)+1)}
+}
+
+// Observe добавляет наблюдение v.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v)
+	h.counts[i].Inc()
+	h.count.Inc()
+	addFloat(&h.sum, v)
+}
+
+// Count возвращает число наблюдений.
+func (h *Histogram) Count() int {
+	return h.count.Value()
+}
+
+// Sum возвращает сумму наблюдений.
+func (h *Histogram) Sum() float64 {
+	return math.Float64frombits(h.sum.Load())
+}
+
+// addFloat атомарно прибавляет delta к float64, хранящемуся в bits.
+func addFloat(bits *atomic.Uint64, delta float64) {
+	for {
+		old := bits.Load()
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if bits.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestRegistryReturnsSameSeries(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	a := r.Counter("requests_total", "", Labels{"method": "GET", "code": "200"})
+	b := r.Counter("requests_total", "", Labels{"code": "200", "method": "GET"})
+	c := r.Counter("requests_total", "", Labels{"method": "POST", "code": "200"})
+	if a != b {
+		t.Error("одинаковые метки в разном порядке должны давать один ряд")
+	}
+	if a == c {
+		t.Error("разные метки должны давать разные ряды")
+	}
+}
+
+func TestRegistryConcurrentAccess(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Counter("hits_total", "", Labels{"path": "/"}).Inc()
+				r.Gauge("inflight", "", nil).Add(0.5)
+				r.Histogram("latency_seconds", "", nil, nil).Observe(0.25)
+			}
+		}()
+	}
+	wg.Wait()
+	if v := r.Counter("hits_total", "", Labels{"path": "/"}).Value(); v != 5000 {
+		t.Errorf("ожидалось 5000, получено %d", v)
+	}
+	if v := r.Gauge("inflight", "", nil).Value(); v != 2500 {
+		t.Errorf("ожидалось 2500, получено %v", v)
+	}
+	h := r.Histogram("latency_seconds", "", nil, nil)
+	if h.Count() != 5000 || math.Abs(h.Sum()-1250) > 1e-9 {
+		t.Errorf("ожидалось 5000 наблюдений с суммой 1250, получено %d и %v", h.Count(), h.Sum())
+	}
+}
+
+func TestGauge(t *testing.T) {
+	t.Parallel()
+	var g Gauge
+	g.Set(10)
+	g.Inc()
+	g.Dec()
+	g.Dec()
+	g.Add(-0.5)
+	if v := g.Value(); v != 8.5 {
+		t.Errorf("ожидалось 8.5, получено %v", v)
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	t.Parallel()
+	cases := map[string]func(r *Registry){
+		"invalid name":    func(r *Registry) { r.Counter("bad-name", "", nil) },
+		"invalid label":   func(r *Registry) { r.Counter("ok", "", Labels{"bad-label": "x"}) },
+		"reserved label":  func(r *Registry) { r.Counter("ok", "", Labels{"__name": "x"}) },
+		"kind conflict":   func(r *Registry) { r.Counter("m", "", nil); r.Gauge("m", "", nil) },
+		"negative add":    func(r *Registry) { r.Counter("c", "", nil).Add(-1) },
+		"conflict labels": func(r *Registry) { r.Gauge("g", "", Labels{"a": "1"}); r.Histogram("g", "", nil, Labels{"a": "2"}) },
+	}
+	for name, f := range cases {
+		f := f
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("ожидалась паника")
+				}
+			}()
+			f(NewRegistry())
+		})
+	}
+}
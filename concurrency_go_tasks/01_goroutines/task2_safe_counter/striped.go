@@ -0,0 +1,81 @@
+package counter
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// cacheLineSize — размер, до которого дополняется каждая ячейка, чтобы
+// соседние ячейки не делили кэш-линию.
+const cacheLineSize = 64
+
+type stripe struct {
+	v atomic.Int64
+	_ [cacheLineSize - 8]byte
+}
+
+// StripedCounter распределяет обновления по нескольким ячейкам и
+// суммирует их при чтении. Запись почти не конфликтует между ядрами,
+// зато Value дороже и не является мгновенным снимком при параллельных
+// обновлениях. CompareAndSwap не поддерживается: атомарно сравнить сумму
+// ячеек невозможно. Нулевое значение готово к использованию: ячейки
+// создаются при первом обращении.
+type StripedCounter struct {
+	once    sync.Once
+	stripes []stripe
+	mask    uint32
+}
+
+// NewStriped создаёт счётчик с числом ячеек, равным GOMAXPROCS,
+// округлённым вверх до степени двойки.
+func NewStriped() *StripedCounter {
+	c := &StripedCounter{}
+	c.cells()
+	return c
+}
+
+// cells возвращает ячейки, создавая их при первом вызове.
+func (c *StripedCounter) cells() []stripe {
+	c.once.Do(func() {
+		n := 1
+		for n < runtime.GOMAXPROCS(0) {
+			n <<= 1
+		}
+		c.stripes, c.mask = make([]stripe, n), uint32(n-1)
+	})
+	return c.stripes
+}
+
+// Inc увеличивает счётчик на 1.
+func (c *StripedCounter) Inc() {
+	c.Add(1)
+}
+
+// Add прибавляет delta к случайной ячейке. Go не даёт узнать номер
+// текущего процессора, а дешёвый случайный выбор распределяет нагрузку
+// не хуже привязки к горутине.
+func (c *StripedCounter) Add(delta int) {
+	cells := c.cells()
+	cells[rand.Uint32()&c.mask].v.Add(int64(delta))
+}
+
+// Value возвращает сумму всех ячеек.
+func (c *StripedCounter) Value() int {
+	var sum int64
+	cells := c.cells()
+	for i := range cells {
+		sum += cells[i].v.Load()
+	}
+	return int(sum)
+}
+
+// Reset обнуляет все ячейки. Обновления, идущие одновременно со сбросом,
+// могут сохраниться.
+func (c *StripedCounter) Reset() {
+	cells := c.cells()
+	for i := range cells {
+		cells[i].v.Store(0)
+	}
+}
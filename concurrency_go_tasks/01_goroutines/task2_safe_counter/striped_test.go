@@ -0,0 +1,54 @@
+package counter
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestNewStripedPowerOfTwo(t *testing.T) {
+	t.Parallel()
+	c := NewStriped()
+	n := len(c.stripes)
+	if n < runtime.GOMAXPROCS(0) {
+		t.Errorf("ячеек (%d) должно быть не меньше GOMAXPROCS (%d)", n, runtime.GOMAXPROCS(0))
+	}
+	if n&(n-1) != 0 || c.mask != uint32(n-1) {
+		t.Errorf("число ячеек должно быть степенью двойки, получено %d (mask %d)", n, c.mask)
+	}
+}
+
+func TestStripedCounterSpreadsUpdates(t *testing.T) {
+	t.Parallel()
+	c := NewStriped()
+	if len(c.stripes) == 1 {
+		t.Skip("при GOMAXPROCS=1 ячейка одна")
+	}
+	for i := 0; i < 10000; i++ {
+		c.Inc()
+	}
+	used := 0
+	for i := range c.stripes {
+		if c.stripes[i].v.Load() > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		t.Errorf("обновления должны распределяться по ячейкам, задействовано %d", used)
+	}
+	if v := c.Value(); v != 10000 {
+		t.Errorf("ожидалась сумма 10000, получено %d", v)
+	}
+}
+
+func TestStripedCounterZeroValue(t *testing.T) {
+	t.Parallel()
+	var c StripedCounter
+	if v := c.Value(); v != 0 {
+		t.Fatalf("нулевой счётчик должен быть равен 0, получено %d", v)
+	}
+	c.Add(5)
+	c.Inc()
+	if v := c.Value(); v != 6 {
+		t.Errorf("ожидалось 6, получено %d", v)
+	}
+}
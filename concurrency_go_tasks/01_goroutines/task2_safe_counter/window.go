@@ -0,0 +1,124 @@
+package counter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Window считает события в кольце временных корзин и отвечает на вопрос
+// «сколько событий было за последние d». Дополнительно он ведёт
+// экспоненциально взвешенную скользящую среднюю (EWMA) частоты событий.
+type Window struct {
+	mu         sync.Mutex
+	resolution time.Duration
+	buckets    []int64
+	head       int       // индекс текущей, ещё не закрытой корзины
+	headStart  time.Time // начало текущей корзины
+	alpha      float64   // вес новой корзины в EWMA
+	rate       float64   // EWMA в событиях в секунду
+	rateSet    bool
+	now        func() time.Time
+}
+
+// WindowOption настраивает Window при создании.
+type WindowOption func(*Window)
+
+// WithEWMAWindow задаёт постоянную времени EWMA: вклад корзины
+// уменьшается в e раз за tau. По умолчанию — одна минута.
+func WithEWMAWindow(tau time.Duration) WindowOption {
+	return func(w *Window) {
+		w.alpha = 1 - math.Exp(-float64(w.resolution)/float64(tau))
+	}
+}
+
+// NewWindow создаёт счётчик с корзинами длительностью resolution,
+// хранящий историю за span. Например, NewWindow(time.Second, 5*time.Minute)
+// позволяет узнать число событий за последние 1s, 1m и 5m.
+func NewWindow(resolution, span time.Duration, opts ...WindowOption) *Window {
+	if resolution <= 0 || span < resolution {
+		panic("counter: window span must be at least one positive resolution")
+	}
+	n := int((span + resolution - 1) / resolution)
+	w := &Window{
+		resolution: resolution,
+		buckets:    make([]int64, n),
+		now:        time.Now,
+	}
+	WithEWMAWindow(time.Minute)(w)
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.headStart = w.now().Truncate(resolution)
+	return w
+}
+
+// Inc регистрирует одно событие.
+func (w *Window) Inc() {
+	w.Add(1)
+}
+
+// Add регистрирует n событий.
+func (w *Window) Add(n int) {
+	w.mu.Lock()
+	w.advance(w.now())
+	w.buckets[w.head] += int64(n)
+	w.mu.Unlock()
+}
+
+// Count возвращает число событий за последние d, включая текущую
+// незакрытую корзину. d округляется вверх до целого числа корзин и
+// ограничивается длиной истории.
+func (w *Window) Count(d time.Duration) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.advance(w.now())
+	k := int((d + w.resolution - 1) / w.resolution)
+	if k > len(w.buckets) {
+		k = len(w.buckets)
+	}
+	var sum int64
+	for i := 0; i < k; i++ {
+		sum += w.buckets[(w.head-i+len(w.buckets))%len(w.buckets)]
+	}
+	return int(sum)
+}
+
+// Rate возвращает EWMA частоты событий в секунду по закрытым корзинам.
+func (w *Window) Rate() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.advance(w.now())
+	return w.rate
+}
+
+// advance закрывает корзины, время которых прошло, обновляя EWMA.
+// Вызывается под w.mu.
+func (w *Window) advance(now time.Time) {
+	ticks := int64(now.Sub(w.headStart) / w.resolution)
+	if ticks <= 0 {
+		return
+	}
+
+	// Закрытая корзина вносит свою частоту, а пустые корзины после неё
+	// лишь затухают, что можно посчитать одной степенью.
+	instant := float64(w.buckets[w.head]) / w.resolution.Seconds()
+	if w.rateSet {
+		w.rate += w.alpha * (instant - w.rate)
+	} else {
+		w.rate, w.rateSet = instant, true
+	}
+	if ticks > 1 {
+		w.rate *= math.Pow(1-w.alpha, float64(ticks-1))
+	}
+
+	expired := ticks
+	if expired > int64(len(w.buckets)) {
+		expired = int64(len(w.buckets))
+	}
+	for i := int64(0); i < expired; i++ {
+		w.head = (w.head + 1) % len(w.buckets)
+		w.buckets[w.head] = 0
+	}
+	w.headStart = w.headStart.Add(time.Duration(ticks) * w.resolution)
+}
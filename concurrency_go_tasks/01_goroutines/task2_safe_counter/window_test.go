@@ -0,0 +1,117 @@
+package counter
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+type windowClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *windowClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *windowClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func newTestWindow(resolution, span time.Duration, opts ...WindowOption) (*Window, *windowClock) {
+	clock := &windowClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	w := NewWindow(resolution, span, opts...)
+	w.now = clock.Now
+	w.headStart = clock.Now()
+	return w, clock
+}
+
+func TestWindowCount(t *testing.T) {
+	t.Parallel()
+	w, clock := newTestWindow(time.Second, 5*time.Minute)
+
+	w.Add(5)
+	clock.Advance(30 * time.Second)
+	w.Add(3)
+	clock.Advance(500 * time.Millisecond)
+	w.Inc()
+
+	if n := w.Count(time.Second); n != 4 {
+		t.Errorf("за последнюю секунду ожидалось 4, получено %d", n)
+	}
+	if n := w.Count(time.Minute); n != 9 {
+		t.Errorf("за минуту ожидалось 9, получено %d", n)
+	}
+
+	clock.Advance(time.Minute)
+	if n := w.Count(time.Minute); n != 0 {
+		t.Errorf("через минуту без событий ожидалось 0, получено %d", n)
+	}
+	if n := w.Count(5 * time.Minute); n != 9 {
+		t.Errorf("за 5 минут ожидалось 9, получено %d", n)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if n := w.Count(5 * time.Minute); n != 0 {
+		t.Errorf("события старше истории должны забываться, получено %d", n)
+	}
+	if n := w.Count(time.Hour); n != 0 {
+		t.Errorf("запрос длиннее истории ограничивается ею, получено %d", n)
+	}
+}
+
+func TestWindowRate(t *testing.T) {
+	t.Parallel()
+	w, clock := newTestWindow(time.Second, time.Minute, WithEWMAWindow(10*time.Second))
+
+	// Постоянный поток в 10 событий в секунду.
+	for i := 0; i < 60; i++ {
+		w.Add(10)
+		clock.Advance(time.Second)
+	}
+	if r := w.Rate(); math.Abs(r-10) > 1e-9 {
+		t.Errorf("при постоянном потоке EWMA должна равняться 10/с, получено %v", r)
+	}
+
+	// После паузы в одну постоянную времени частота падает в e раз.
+	clock.Advance(10 * time.Second)
+	if r, want := w.Rate(), 10/math.E; math.Abs(r-want) > 1e-9 {
+		t.Errorf("ожидалось %v, получено %v", want, r)
+	}
+}
+
+func TestWindowConcurrent(t *testing.T) {
+	t.Parallel()
+	w := NewWindow(time.Second, time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				w.Inc()
+				w.Count(time.Second)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := w.Count(time.Minute); n != 5000 {
+		t.Errorf("ожидалось 5000, получено %d", n)
+	}
+}
+
+func TestNewWindowValidation(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Error("ожидалась паника при span меньше resolution")
+		}
+	}()
+	NewWindow(time.Minute, time.Second)
+}
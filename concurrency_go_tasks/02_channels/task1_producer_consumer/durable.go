@@ -0,0 +1,422 @@
+package producerconsumer
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrCorrupt = errors.New("producerconsumer: corrupt queue segment") // повреждён не последний сегмент журнала
+var ErrTooLarge = errors.New("producerconsumer: message too large")    // сообщение больше maxRecordSize
+var ErrUnknownID = errors.New("producerconsumer: unknown message id")  // Ack для сообщения, которого ещё нет
+
+const (
+	acksName           = "acks.log"
+	ackRecord          = 12 // [id uint64][crc32 uint32]
+	defaultSegmentSize = 4 << 20
+)
+
+// Message — сообщение из DiskQueue. ID монотонно растёт и не меняется
+// после перезапуска.
+type Message struct {
+	ID   uint64
+	Data []byte
+}
+
+// DiskOption настраивает DiskQueue при открытии.
+type DiskOption func(*DiskQueue)
+
+// WithSegmentSize задаёт размер, после которого начинается новый
+// сегмент. Сегмент удаляется целиком, когда подтверждены все его
+// сообщения, поэтому меньший размер быстрее освобождает диск.
+func WithSegmentSize(bytes int64) DiskOption {
+	return func(q *DiskQueue) {
+		q.segmentSize = bytes
+	}
+}
+
+// WithSyncWrites включает fsync после каждой записи. Без него записанное
+// переживает падение процесса, но не отключение питания.
+func WithSyncWrites() DiskOption {
+	return func(q *DiskQueue) {
+		q.syncWrites = true
+	}
+}
+
+// DiskQueue — очередь в каталоге на локальном диске: сообщения
+// дописываются в журнал из сегментов, а подтверждения — в отдельный файл
+// acks.log. Доставка «как минимум один раз»: сообщение, не
+// подтверждённое через Ack, после перезапуска будет выдано снова.
+// Каталог должен использоваться только одним экземпляром DiskQueue.
+type DiskQueue struct {
+	mu          sync.Mutex
+	dir         string
+	segmentSize int64
+	syncWrites  bool
+
+	segs   []*segment // по возрастанию base, последний открыт на запись
+	acks   *os.File
+	acked  map[uint64]struct{} // подтверждённые ID в живых сегментах
+	nextID uint64
+
+	// Курсор чтения: следующий ID и его место в журнале.
+	rid  uint64
+	rseg int
+	roff int64
+
+	closed   bool
+	notEmpty chan struct{}
+}
+
+// OpenDiskQueue открывает очередь в dir, создавая каталог при
+// необходимости. После падения оборванный хвост журнала отбрасывается,
+// а неподтверждённые сообщения снова становятся доступны для Get.
+func OpenDiskQueue(dir string, opts ...DiskOption) (*DiskQueue, error) {
+	q := &DiskQueue{
+		dir:         dir,
+		segmentSize: defaultSegmentSize,
+		acked:       make(map[uint64]struct{}),
+		notEmpty:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := q.recover(); err != nil {
+		q.closeFiles()
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *DiskQueue) recover() error {
+	bases, err := q.listSegments()
+	if err != nil {
+		return err
+	}
+	if len(bases) == 0 {
+		bases = []uint64{1}
+	}
+	for i, base := range bases {
+		s, err := openSegment(filepath.Join(q.dir, segmentName(base)), base)
+		if err != nil {
+			return err
+		}
+		q.segs = append(q.segs, s)
+		if err := s.recover(i == len(bases)-1); err != nil {
+			return err
+		}
+		if i > 0 {
+			prev := q.segs[i-1]
+			if prev.base+prev.count != base {
+				return fmt.Errorf("%w: gap before %s", ErrCorrupt, s.path)
+			}
+		}
+	}
+	last := q.segs[len(q.segs)-1]
+	q.nextID = last.base + last.count
+
+	if err := q.loadAcks(); err != nil {
+		return err
+	}
+	q.rid, q.rseg, q.roff = q.segs[0].base, 0, 0
+	return q.compact()
+}
+
+func (q *DiskQueue) listSegments() ([]uint64, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	var bases []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".seg")
+		if !ok || e.IsDir() {
+			continue
+		}
+		base, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+	return bases, nil
+}
+
+// loadAcks читает подтверждения, обрезая оборванную последнюю запись.
+func (q *DiskQueue) loadAcks() error {
+	f, err := os.OpenFile(filepath.Join(q.dir, acksName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	q.acks = f
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return err
+	}
+	var off int
+	for ; off+ackRecord <= len(data); off += ackRecord {
+		rec := data[off : off+ackRecord]
+		if crc32.ChecksumIEEE(rec[:8]) != binary.BigEndian.Uint32(rec[8:]) {
+			break
+		}
+		q.markAcked(binary.BigEndian.Uint64(rec[:8]))
+	}
+	if off < len(data) {
+		if err := f.Truncate(int64(off)); err != nil {
+			return err
+		}
+	}
+	_, err = f.Seek(int64(off), 0)
+	return err
+}
+
+// markAcked учитывает подтверждение. Вызывается под q.mu.
+func (q *DiskQueue) markAcked(id uint64) bool {
+	s := q.segmentOf(id)
+	if s == nil {
+		return false
+	}
+	if _, ok := q.acked[id]; ok {
+		return false
+	}
+	q.acked[id] = struct{}{}
+	s.acked++
+	return true
+}
+
+// segmentOf ищет сегмент с сообщением id.
+func (q *DiskQueue) segmentOf(id uint64) *segment {
+	i := sort.Search(len(q.segs), func(i int) bool {
+		return q.segs[i].base+q.segs[i].count > id
+	})
+	if i == len(q.segs) || id < q.segs[i].base {
+		return nil
+	}
+	return q.segs[i]
+}
+
+// Put дописывает сообщение в журнал и возвращает его ID.
+func (q *DiskQueue) Put(data []byte) (uint64, error) {
+	if len(data) > maxRecordSize {
+		return 0, ErrTooLarge
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return 0, ErrClosed
+	}
+	s := q.segs[len(q.segs)-1]
+	if s.size >= q.segmentSize && s.count > 0 {
+		var err error
+		if s, err = openSegment(filepath.Join(q.dir, segmentName(q.nextID)), q.nextID); err != nil {
+			return 0, err
+		}
+		q.segs = append(q.segs, s)
+	}
+	if err := s.append(data); err != nil {
+		return 0, err
+	}
+	if q.syncWrites {
+		if err := s.f.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	id := q.nextID
+	q.nextID++
+	broadcast(&q.notEmpty)
+	return id, nil
+}
+
+// Get возвращает следующее неподтверждённое сообщение, ожидая его
+// появления. Выданное сообщение не выдаётся повторно до перезапуска,
+// даже если не подтверждено. После Close возвращается ErrClosed.
+func (q *DiskQueue) Get(ctx context.Context) (Message, error) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return Message{}, ErrClosed
+		}
+		msg, ok, err := q.read()
+		if ok || err != nil {
+			q.mu.Unlock()
+			return msg, err
+		}
+		wait := q.notEmpty
+		q.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		}
+	}
+}
+
+// read продвигает курсор до первого неподтверждённого сообщения.
+// Вызывается под q.mu.
+func (q *DiskQueue) read() (Message, bool, error) {
+	for q.rid < q.nextID {
+		s := q.segs[q.rseg]
+		if q.roff >= s.size {
+			q.rseg++
+			q.roff = 0
+			continue
+		}
+		data, next, err := s.readAt(q.roff)
+		if err != nil {
+			return Message{}, false, err
+		}
+		id := q.rid
+		q.rid++
+		q.roff = next
+		if _, ok := q.acked[id]; ok {
+			continue
+		}
+		return Message{ID: id, Data: data}, true, nil
+	}
+	return Message{}, false, nil
+}
+
+// Ack подтверждает обработку сообщения. Полностью подтверждённые
+// сегменты, кроме текущего сегмента записи, удаляются с диска.
+// Повторное подтверждение ничего не делает.
+func (q *DiskQueue) Ack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	if id >= q.nextID || id == 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownID, id)
+	}
+	if !q.markAcked(id) {
+		return nil // уже подтверждено или удалено при уплотнении
+	}
+	var rec [ackRecord]byte
+	binary.BigEndian.PutUint64(rec[:8], id)
+	binary.BigEndian.PutUint32(rec[8:], crc32.ChecksumIEEE(rec[:8]))
+	if _, err := q.acks.Write(rec[:]); err != nil {
+		return err
+	}
+	if q.syncWrites {
+		if err := q.acks.Sync(); err != nil {
+			return err
+		}
+	}
+	return q.compact()
+}
+
+// compact удаляет полностью подтверждённые сегменты в начале журнала и
+// переписывает acks.log без относящихся к ним записей. Вызывается под q.mu.
+func (q *DiskQueue) compact() error {
+	n := 0
+	for n < len(q.segs)-1 && q.segs[n].acked == q.segs[n].count {
+		s := q.segs[n]
+		s.f.Close()
+		if err := os.Remove(s.path); err != nil {
+			return err
+		}
+		for id := s.base; id < s.base+s.count; id++ {
+			delete(q.acked, id)
+		}
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	q.segs = q.segs[n:]
+	if q.rseg < n {
+		q.rid, q.rseg, q.roff = q.segs[0].base, 0, 0
+	} else {
+		q.rseg -= n
+	}
+	return q.rewriteAcks()
+}
+
+// rewriteAcks атомарно заменяет acks.log актуальными подтверждениями.
+func (q *DiskQueue) rewriteAcks() error {
+	ids := make([]uint64, 0, len(q.acked))
+	for id := range q.acked {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	buf := make([]byte, 0, len(ids)*ackRecord)
+	for _, id := range ids {
+		buf = binary.BigEndian.AppendUint64(buf, id)
+		buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf[len(buf)-8:]))
+	}
+
+	path := filepath.Join(q.dir, acksName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return err
+	}
+	if q.syncWrites {
+		if err := syncFile(tmp); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	q.acks.Close()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	q.acks = f
+	return nil
+}
+
+func syncFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+// Len возвращает число неподтверждённых сообщений, включая выданные.
+func (q *DiskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int(q.nextID - q.segs[0].base - uint64(len(q.acked)))
+}
+
+// Close закрывает файлы очереди и пробуждает ожидающих в Get.
+func (q *DiskQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	broadcast(&q.notEmpty)
+	return q.closeFiles()
+}
+
+func (q *DiskQueue) closeFiles() error {
+	var errs []error
+	for _, s := range q.segs {
+		errs = append(errs, s.f.Close())
+	}
+	if q.acks != nil {
+		errs = append(errs, q.acks.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,209 @@
+package producerconsumer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func openTestQueue(t *testing.T, dir string, opts ...DiskOption) *DiskQueue {
+	t.Helper()
+	q, err := OpenDiskQueue(dir, opts...)
+	if err != nil {
+		t.Fatalf("OpenDiskQueue: %v", err)
+	}
+	t.Cleanup(func() { q.Close() })
+	return q
+}
+
+func mustGet(t *testing.T, q *DiskQueue) Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	m, err := q.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	return m
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestDiskQueuePutGetAck(t *testing.T) {
+	t.Parallel()
+	q := openTestQueue(t, t.TempDir())
+	for i := 0; i < 3; i++ {
+		if _, err := q.Put([]byte("m" + strconv.Itoa(i))); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		m := mustGet(t, q)
+		if string(m.Data) != "m"+strconv.Itoa(i) {
+			t.Fatalf("ожидалось m%d, получено %q", i, m.Data)
+		}
+		if err := q.Ack(m.ID); err != nil {
+			t.Fatalf("Ack: %v", err)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("после подтверждения всех сообщений Len должен быть 0, получено %d", q.Len())
+	}
+	if err := q.Ack(100); !errors.Is(err, ErrUnknownID) {
+		t.Errorf("ожидалась ErrUnknownID, получено %v", err)
+	}
+}
+
+func TestDiskQueueRedeliversUnackedAfterRestart(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q, err := OpenDiskQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"a", "b", "c"} {
+		q.Put([]byte(s))
+	}
+	a := mustGet(t, q)
+	mustGet(t, q) // b выдано, но не подтверждено
+	q.Ack(a.ID)
+	q.Close()
+
+	q = openTestQueue(t, dir)
+	if q.Len() != 2 {
+		t.Errorf("ожидалось 2 неподтверждённых, получено %d", q.Len())
+	}
+	for _, want := range []string{"b", "c"} {
+		if m := mustGet(t, q); string(m.Data) != want {
+			t.Errorf("ожидалось %q, получено %q", want, m.Data)
+		}
+	}
+	id, _ := q.Put([]byte("d"))
+	if id != 4 {
+		t.Errorf("ID должны продолжаться после перезапуска: ожидалось 4, получено %d", id)
+	}
+}
+
+func TestDiskQueueTruncatesTornTail(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q, err := OpenDiskQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Put([]byte("whole"))
+	q.Put([]byte("torn"))
+	q.Close()
+
+	// Имитируем падение посреди записи второго сообщения.
+	seg := segmentFiles(t, dir)[0]
+	info, _ := os.Stat(seg)
+	if err := os.Truncate(seg, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+	acks := filepath.Join(dir, acksName)
+	if err := os.WriteFile(acks, []byte{0, 0, 0}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	q = openTestQueue(t, dir)
+	if q.Len() != 1 {
+		t.Fatalf("оборванная запись должна быть отброшена, Len = %d", q.Len())
+	}
+	if m := mustGet(t, q); string(m.Data) != "whole" || m.ID != 1 {
+		t.Errorf("ожидалось сообщение 1 whole, получено %d %q", m.ID, m.Data)
+	}
+	if id, _ := q.Put([]byte("next")); id != 2 {
+		t.Errorf("ожидался ID 2, получено %d", id)
+	}
+	if m := mustGet(t, q); string(m.Data) != "next" {
+		t.Errorf("ожидалось next, получено %q", m.Data)
+	}
+	if err := q.Ack(1); err != nil {
+		t.Errorf("Ack после обрезки журнала подтверждений: %v", err)
+	}
+}
+
+func TestDiskQueueCompactsAckedSegments(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q := openTestQueue(t, dir, WithSegmentSize(32))
+	var ids []uint64
+	for i := 0; i < 10; i++ {
+		id, err := q.Put([]byte("0123456789"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	before := len(segmentFiles(t, dir))
+	if before < 3 {
+		t.Fatalf("ожидалось несколько сегментов, получено %d", before)
+	}
+
+	// Подтверждения в обратном порядке: сегменты удаляются, только
+	// когда подтверждено всё, что в них есть.
+	for i := len(ids) - 1; i >= 1; i-- {
+		q.Ack(ids[i])
+	}
+	if got := len(segmentFiles(t, dir)); got != before {
+		t.Errorf("сегмент с неподтверждённым сообщением не должен удаляться: было %d, стало %d", before, got)
+	}
+	q.Ack(ids[0])
+	if got := len(segmentFiles(t, dir)); got != 1 {
+		t.Errorf("должен остаться только сегмент записи, осталось %d", got)
+	}
+	q.Close()
+
+	q = openTestQueue(t, dir, WithSegmentSize(32))
+	if q.Len() != 0 {
+		t.Errorf("после перезапуска не должно быть сообщений, Len = %d", q.Len())
+	}
+	if id, _ := q.Put([]byte("x")); id != 11 {
+		t.Errorf("ожидался ID 11, получено %d", id)
+	}
+}
+
+func TestDiskQueueGetWaits(t *testing.T) {
+	t.Parallel()
+	q := openTestQueue(t, t.TempDir())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get из пустой очереди должен ждать до отмены ctx, получено %v", err)
+	}
+
+	got := make(chan Message)
+	go func() {
+		m, _ := q.Get(context.Background())
+		got <- m
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Put([]byte("late"))
+	if m := <-got; string(m.Data) != "late" {
+		t.Errorf("ожидалось late, получено %q", m.Data)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := q.Get(context.Background())
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("Close должен пробуждать Get с ErrClosed, получено %v", err)
+	}
+}
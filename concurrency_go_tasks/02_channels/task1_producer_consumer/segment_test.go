@@ -0,0 +1,60 @@
+package producerconsumer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSegmentRecordRoundTrip(t *testing.T) {
+	t.Parallel()
+	s, err := openSegment(filepath.Join(t.TempDir(), segmentName(1)), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.f.Close()
+	for _, d := range []string{"first", "", "third"} {
+		if err := s.append([]byte(d)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var off int64
+	for _, want := range []string{"first", "", "third"} {
+		data, next, err := s.readAt(off)
+		if err != nil || string(data) != want {
+			t.Fatalf("ожидалось %q, получено %q, %v", want, data, err)
+		}
+		off = next
+	}
+	if _, _, err := s.readAt(off); !errors.Is(err, errTorn) {
+		t.Errorf("чтение за концом сегмента: ожидалась errTorn, получено %v", err)
+	}
+}
+
+func TestSegmentDetectsChecksumMismatch(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), segmentName(1))
+	s, err := openSegment(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.append([]byte("payload"))
+	s.f.Close()
+
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path, data, 0o644)
+
+	s, _ = openSegment(path, 1)
+	defer s.f.Close()
+	if _, _, err := s.readAt(0); !errors.Is(err, errTorn) {
+		t.Errorf("ожидалась errTorn, получено %v", err)
+	}
+	if err := s.recover(false); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("повреждение не последнего сегмента: ожидалась ErrCorrupt, получено %v", err)
+	}
+	if err := s.recover(true); err != nil || s.size != 0 {
+		t.Errorf("последний сегмент должен обрезаться: size %d, %v", s.size, err)
+	}
+}
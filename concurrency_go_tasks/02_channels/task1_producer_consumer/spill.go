@@ -0,0 +1,112 @@
+package producerconsumer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"io"
+	"os"
+)
+
+// spillCompactAt — сколько байт прочитанных записей может накопиться в
+// начале файла, прежде чем он будет уплотнён.
+const spillCompactAt = 1 << 20
+
+// spill — FIFO во временном файле из записей [длина uint32][gob].
+// Файл создаётся при первой записи и удаляется, как только все записи
+// прочитаны, поэтому на диске он существует только при отставании
+// консюмеров. Если консюмеры отстают постоянно, прочитанное начало файла
+// вырезается, когда занимает не меньше compactAt байт и не меньше
+// половины файла. Методы вызываются под мьютексом очереди.
+type spill[T any] struct {
+	dir       string
+	f         *os.File
+	r, w      int64 // смещения чтения и записи
+	n         int   // непрочитанные записи
+	compactAt int64
+}
+
+func (s *spill[T]) write(v T) error {
+	if s.f == nil {
+		f, err := os.CreateTemp(s.dir, "queue-spill-*")
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 4))
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return err
+	}
+	rec := buf.Bytes()
+	binary.BigEndian.PutUint32(rec, uint32(len(rec)-4))
+	if _, err := s.f.WriteAt(rec, s.w); err != nil {
+		return err
+	}
+	s.w += int64(len(rec))
+	s.n++
+	return nil
+}
+
+// read извлекает самую старую запись. Если запись не удалось прочитать,
+// она всё равно считается извлечённой, чтобы очередь не застряла на ней.
+func (s *spill[T]) read() (T, error) {
+	var v T
+	var hdr [4]byte
+	if _, err := s.f.ReadAt(hdr[:], s.r); err != nil {
+		s.drop()
+		return v, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+	if _, err := s.f.ReadAt(payload, s.r+4); err != nil {
+		s.drop()
+		return v, err
+	}
+	s.r += 4 + int64(len(payload))
+	s.n--
+	if s.n == 0 {
+		s.remove()
+	} else if s.r >= s.compactAt && s.r >= s.w-s.r {
+		if err := s.compact(); err != nil {
+			s.drop()
+			return v, err
+		}
+	}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// compact переносит непрочитанные записи в начало файла и обрезает его.
+// Прочитанная часть не короче оставшейся, поэтому копирование вперёд не
+// затирает ещё не скопированные данные.
+func (s *spill[T]) compact() error {
+	live := s.w - s.r
+	if _, err := io.Copy(io.NewOffsetWriter(s.f, 0), io.NewSectionReader(s.f, s.r, live)); err != nil {
+		return err
+	}
+	if err := s.f.Truncate(live); err != nil {
+		return err
+	}
+	s.r, s.w = 0, live
+	return nil
+}
+
+// drop отбрасывает всё содержимое файла после ошибки чтения: границы
+// следующих записей уже не известны.
+func (s *spill[T]) drop() {
+	s.n = 0
+	s.remove()
+}
+
+func (s *spill[T]) remove() {
+	if s.f == nil {
+		return
+	}
+	name := s.f.Name()
+	s.f.Close()
+	os.Remove(name)
+	s.f, s.r, s.w = nil, 0, 0
+}
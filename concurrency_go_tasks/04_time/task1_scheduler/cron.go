@@ -0,0 +1,271 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrBadSpec = errors.New("scheduler: invalid cron expression") // выражение не удалось разобрать
+
+// Schedule вычисляет моменты запуска задачи.
+type Schedule interface {
+	// Next возвращает первый момент запуска строго после t или нулевое
+	// время, если запусков больше не будет.
+	Next(t time.Time) time.Time
+}
+
+// macros — сокращения для распространённых расписаний.
+var macros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// field описывает допустимый диапазон одного поля выражения.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = field{name: "second", min: 0, max: 59}
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 допускается как второе обозначение воскресенья.
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// ParseCron разбирает выражение cron из 5 полей (минута, час, день
+// месяца, месяц, день недели) или из 6 полей с секундами в начале.
+// Поддерживаются *, ?, списки через запятую, диапазоны a-b, шаги /n,
+// названия месяцев и дней недели, макросы @daily, @hourly и т.п., а также
+// @every <длительность>. Часовой пояс задаётся префиксом
+// CRON_TZ=Europe/Moscow; без него расписание считается в поясе времени,
+// переданного в Next.
+func ParseCron(spec string) (Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	var loc *time.Location
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		tz, rest, _ := strings.Cut(expr, " ")
+		_, name, _ := strings.Cut(tz, "=")
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrBadSpec, spec, err)
+		}
+		loc, expr = l, strings.TrimSpace(rest)
+	}
+
+	if strings.HasPrefix(expr, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(expr[len("@every "):]))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: %q: bad @every duration", ErrBadSpec, spec)
+		}
+		return constantDelay(d), nil
+	}
+	if m, ok := macros[expr]; ok {
+		expr = m
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: %q: expected 5 or 6 fields, got %d", ErrBadSpec, spec, len(fields))
+	}
+
+	c := &cronSchedule{loc: loc}
+	var err error
+	parse := func(dst *uint64, text string, f field) bool {
+		if err != nil {
+			return false
+		}
+		var star bool
+		*dst, star, err = f.parse(text)
+		if err != nil {
+			err = fmt.Errorf("%w: %q: %v", ErrBadSpec, spec, err)
+		}
+		return star
+	}
+	parse(&c.second, fields[0], secondField)
+	parse(&c.minute, fields[1], minuteField)
+	parse(&c.hour, fields[2], hourField)
+	c.domStar = parse(&c.dom, fields[3], domField)
+	parse(&c.month, fields[4], monthField)
+	c.dowStar = parse(&c.dow, fields[5], dowField)
+	if err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 << 0
+	}
+	return c, nil
+}
+
+// parse разбирает поле в битовую маску допустимых значений. star
+// сообщает, что поле не ограничено (* или ?).
+func (f field) parse(text string) (bits uint64, star bool, err error) {
+	for _, part := range strings.Split(text, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*" || rng == "?":
+			star = !hasStep
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			if lo, err = f.value(a); err != nil {
+				return 0, false, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, false, err
+			}
+		default:
+			if lo, err = f.value(rng); err != nil {
+				return 0, false, err
+			}
+			if !hasStep {
+				hi = lo
+			}
+		}
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("%s: bad step %q", f.name, stepText)
+			}
+		}
+		if lo > hi {
+			return 0, false, fmt.Errorf("%s: empty range %q", f.name, part)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
+
+func (f field) value(text string) (int, error) {
+	if v, ok := f.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("%s: bad value %q", f.name, text)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: %d out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// cronSchedule хранит допустимые значения полей в виде битовых масок.
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool
+	loc                                   *time.Location
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// dayMatches применяет правило cron: если ограничены и день месяца, и
+// день недели, достаточно совпадения любого из них.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next перебирает поля от месяца к секунде, сбрасывая младшие поля при
+// переходе старшего. Если расписание не срабатывает ближайшие пять лет
+// (например, 30 февраля), возвращается нулевое время.
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	orig := t.Location()
+	loc := orig
+	if c.loc != nil {
+		loc = c.loc
+	}
+	t = t.In(loc).Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+
+	// reset означает, что время уже сдвигалось и младшие поля обнулены.
+	reset := false
+wrap:
+	for t.Year() <= yearLimit {
+		for !has(c.month, int(t.Month())) {
+			if !reset {
+				reset = true
+				t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+			}
+			t = t.AddDate(0, 1, 0)
+			if t.Month() == time.January {
+				continue wrap
+			}
+		}
+		for !c.dayMatches(t) {
+			if !reset {
+				reset = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+			}
+			t = t.AddDate(0, 0, 1)
+			if t.Day() == 1 {
+				continue wrap
+			}
+		}
+		for !has(c.hour, t.Hour()) {
+			if !reset {
+				reset = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+			}
+			t = t.Add(time.Hour)
+			if t.Hour() == 0 {
+				continue wrap
+			}
+		}
+		for !has(c.minute, t.Minute()) {
+			if !reset {
+				reset = true
+				t = t.Truncate(time.Minute)
+			}
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue wrap
+			}
+		}
+		for !has(c.second, t.Second()) {
+			reset = true
+			t = t.Add(time.Second)
+			if t.Second() == 0 {
+				continue wrap
+			}
+		}
+		return t.In(orig)
+	}
+	return time.Time{}
+}
+
+// constantDelay — расписание @every: запуски через равные промежутки.
+type constantDelay time.Duration
+
+func (d constantDelay) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
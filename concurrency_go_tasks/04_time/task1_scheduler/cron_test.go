@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, spec string) Schedule {
+	t.Helper()
+	s, err := ParseCron(spec)
+	if err != nil {
+		t.Fatalf("ParseCron(%q): %v", spec, err)
+	}
+	return s
+}
+
+func TestCronNext(t *testing.T) {
+	t.Parallel()
+	utc := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04:05", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		spec, from, want string
+	}{
+		{"* * * * *", "2024-03-10 10:15:30", "2024-03-10 10:16:00"},
+		{"*/15 * * * *", "2024-03-10 10:15:00", "2024-03-10 10:30:00"},
+		{"30 * * * * *", "2024-03-10 10:15:30", "2024-03-10 10:16:30"},
+		{"0 9-17 * * mon-fri", "2024-03-08 17:30:00", "2024-03-11 09:00:00"},
+		{"0 0 1,15 * *", "2024-03-02 00:00:00", "2024-03-15 00:00:00"},
+		{"0 0 * feb *", "2024-03-02 00:00:00", "2025-02-01 00:00:00"},
+		{"0 12 29 2 *", "2024-03-01 00:00:00", "2028-02-29 12:00:00"},
+		{"0 0 * * 7", "2024-03-10 00:00:00", "2024-03-17 00:00:00"},
+		{"5/20 * * * *", "2024-03-10 10:26:00", "2024-03-10 10:45:00"},
+		{"@daily", "2024-12-31 23:59:59", "2025-01-01 00:00:00"},
+		{"@hourly", "2024-03-10 10:00:00", "2024-03-10 11:00:00"},
+		{"@every 90m", "2024-03-10 10:00:00", "2024-03-10 11:30:00"},
+		// День месяца и день недели ограничены оба: подходит любой.
+		{"0 0 13 * fri", "2024-03-02 00:00:00", "2024-03-08 00:00:00"},
+	}
+	for _, tt := range tests {
+		got := mustParse(t, tt.spec).Next(utc(tt.from))
+		if want := utc(tt.want); !got.Equal(want) {
+			t.Errorf("%q после %s: ожидалось %s, получено %s", tt.spec, tt.from, want, got)
+		}
+	}
+}
+
+func TestCronNeverFires(t *testing.T) {
+	t.Parallel()
+	if next := mustParse(t, "0 0 30 2 *").Next(time.Now()); !next.IsZero() {
+		t.Errorf("30 февраля не наступает, получено %s", next)
+	}
+}
+
+func TestCronTimeZone(t *testing.T) {
+	t.Parallel()
+	s := mustParse(t, "CRON_TZ=Asia/Tokyo 0 9 * * *")
+	from := time.Date(2024, 3, 10, 0, 30, 0, 0, time.UTC) // 09:30 в Токио
+	got := s.Next(from)
+	want := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("ожидалось %s, получено %s", want, got)
+	}
+	if got.Location() != time.UTC {
+		t.Errorf("результат должен быть в поясе аргумента, получено %s", got.Location())
+	}
+}
+
+func TestCronDSTGap(t *testing.T) {
+	t.Parallel()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("нет базы часовых поясов")
+	}
+	// 10 марта 2024 часы перевели с 02:00 на 03:00: 02:30 не было.
+	s := mustParse(t, "30 2 * * *")
+	got := s.Next(time.Date(2024, 3, 9, 12, 0, 0, 0, ny))
+	if got.Day() != 11 || got.Hour() != 2 || got.Minute() != 30 {
+		t.Errorf("несуществующее время должно пропускаться, получено %s", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	t.Parallel()
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"x * * * *",
+		"@every -1s",
+		"@sometimes",
+		"CRON_TZ=Mars/Olympus * * * * *",
+	} {
+		if _, err := ParseCron(spec); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("ParseCron(%q): ожидалась ErrBadSpec, получено %v", spec, err)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package scheduler
+
+import "context"
+
+// TryLock не поддерживается на этой платформе.
+func (l *FileLocker) TryLock(context.Context, string) (func() error, bool, error) {
+	return nil, false, ErrLockUnsupported
+}
@@ -0,0 +1,39 @@
+//go:build unix
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// TryLock захватывает эксклюзивную flock-блокировку файла name.lock.
+// Блокировка привязана к открытому файлу и снимается ядром, если
+// процесс завершится, не вызвав unlock.
+func (l *FileLocker) TryLock(_ context.Context, name string) (func() error, bool, error) {
+	f, err := os.OpenFile(l.path(name), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	var once sync.Once
+	var unlockErr error
+	return func() error {
+		once.Do(func() {
+			unlockErr = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+			if err := f.Close(); unlockErr == nil {
+				unlockErr = err
+			}
+		})
+		return unlockErr
+	}, true, nil
+}
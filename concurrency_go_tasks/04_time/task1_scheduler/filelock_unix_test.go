@@ -0,0 +1,40 @@
+//go:build unix
+
+package scheduler
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestFileLocker(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	ctx := context.Background()
+	// Два экземпляра имитируют две реплики: flock действует и между
+	// разными открытиями файла в одном процессе.
+	a, b := NewFileLocker(dir), NewFileLocker(dir)
+
+	unlock, ok, err := a.TryLock(ctx, "nightly/billing")
+	if !ok || err != nil {
+		t.Fatalf("первый захват должен удаться: %v, %v", ok, err)
+	}
+	if _, ok, err := b.TryLock(ctx, "nightly/billing"); ok || err != nil {
+		t.Errorf("занятая блокировка не должна захватываться: %v, %v", ok, err)
+	}
+	if _, err := os.Stat(a.path("nightly/billing")); err != nil {
+		t.Errorf("имя задачи должно экранироваться в имя файла: %v", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := unlock(); err != nil {
+		t.Errorf("повторный unlock: %v", err)
+	}
+	unlock2, ok, err := b.TryLock(ctx, "nightly/billing")
+	if !ok || err != nil {
+		t.Fatalf("после unlock блокировка должна освободиться: %v, %v", ok, err)
+	}
+	unlock2()
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultHistorySize — сколько последних запусков хранится по задаче.
+const defaultHistorySize = 20
+
+// Outcome — итог запуска задачи.
+type Outcome string
+
+const (
+	OutcomeOK      Outcome = "ok"      // задача завершилась без ошибки
+	OutcomeError   Outcome = "error"   // задача вернула ошибку
+	OutcomeSkipped Outcome = "skipped" // тик пропущен политикой перекрытия или блокировкой
+)
+
+// Run — запись истории запусков задачи.
+type Run struct {
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration_ns"`
+	Outcome  Outcome       `json:"outcome"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// WithHistorySize задаёт, сколько последних запусков каждой задачи
+// хранит Scheduler. По умолчанию — 20.
+func WithHistorySize(n int) SchedulerOption {
+	return func(s *Scheduler) {
+		s.historySize = n
+	}
+}
+
+// history — кольцевой буфер последних запусков.
+type history struct {
+	mu    sync.Mutex
+	runs  []Run
+	next  int
+	total int
+}
+
+func newHistory(size int) *history {
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	return &history{runs: make([]Run, size)}
+}
+
+func (h *history) add(r Run) {
+	h.mu.Lock()
+	h.runs[h.next] = r
+	h.next = (h.next + 1) % len(h.runs)
+	h.total++
+	h.mu.Unlock()
+}
+
+// list возвращает запуски от старых к новым.
+func (h *history) list() []Run {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := h.total
+	if n > len(h.runs) {
+		n = len(h.runs)
+	}
+	out := make([]Run, 0, n)
+	for i := n; i > 0; i-- {
+		out = append(out, h.runs[(h.next-i+len(h.runs))%len(h.runs)])
+	}
+	return out
+}
+
+// recordRun заносит в историю завершённый запуск.
+func (h *history) recordRun(start time.Time, err error) {
+	r := Run{Start: start, Duration: time.Since(start), Outcome: OutcomeOK}
+	if err != nil {
+		r.Outcome, r.Error = OutcomeError, err.Error()
+	}
+	h.add(r)
+}
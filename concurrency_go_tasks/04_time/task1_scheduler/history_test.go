@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHistoryRing(t *testing.T) {
+	t.Parallel()
+	h := newHistory(3)
+	if got := h.list(); len(got) != 0 {
+		t.Fatalf("пустая история, получено %v", got)
+	}
+	base := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		h.add(Run{Start: base.Add(time.Duration(i) * time.Minute)})
+	}
+	got := h.list()
+	if len(got) != 3 {
+		t.Fatalf("ожидалось 3 записи, получено %d", len(got))
+	}
+	for i, r := range got {
+		if want := base.Add(time.Duration(i+2) * time.Minute); !r.Start.Equal(want) {
+			t.Errorf("запись %d: ожидалось %s, получено %s", i, want, r.Start)
+		}
+	}
+}
+
+func TestSchedulerHistory(t *testing.T) {
+	t.Parallel()
+	boom := errors.New("boom")
+	s := New(WithHistorySize(5))
+	release := make(chan struct{})
+	calls := 0
+	s.AddJob("job", "@yearly", func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return boom
+		}
+		<-release
+		return nil
+	}, WithOverlap(Skip))
+
+	s.Trigger("job")
+	waitHistory(t, s, "job", 1)
+	s.Trigger("job")
+	time.Sleep(10 * time.Millisecond)
+	if err := s.Trigger("job"); !errors.Is(err, ErrRejected) { // предыдущий запуск ещё идёт
+		t.Errorf("ожидалась ErrRejected, получено %v", err)
+	}
+	close(release)
+	waitHistory(t, s, "job", 3)
+
+	runs, _ := s.History("job")
+	outcomes := []Outcome{runs[0].Outcome, runs[1].Outcome, runs[2].Outcome}
+	want := []Outcome{OutcomeError, OutcomeSkipped, OutcomeOK}
+	for i := range want {
+		if outcomes[i] != want[i] {
+			t.Fatalf("ожидались итоги %v, получено %v", want, outcomes)
+		}
+	}
+	if runs[0].Error != "boom" {
+		t.Errorf("ожидался текст ошибки boom, получено %q", runs[0].Error)
+	}
+	if runs[2].Duration < 5*time.Millisecond {
+		t.Errorf("длительность запуска должна учитываться, получено %s", runs[2].Duration)
+	}
+	if _, err := s.History("missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("ожидалась ErrUnknownJob, получено %v", err)
+	}
+	if err := s.Trigger("missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("ожидалась ErrUnknownJob, получено %v", err)
+	}
+}
+
+func waitHistory(t *testing.T, s *Scheduler, name string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runs, _ := s.History(name); len(runs) >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("за секунду в истории %s не набралось %d записей", name, n)
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// jobStatus — представление задачи в ответах Handler.
+type jobStatus struct {
+	JobInfo
+	History []Run `json:"history"`
+}
+
+// Handler возвращает HTTP-интерфейс для просмотра и ручного запуска
+// задач:
+//
+//	GET  /jobs             — все задачи с историей запусков
+//	GET  /jobs/{name}      — одна задача
+//	POST /jobs/{name}/run  — запустить задачу вне расписания; 409, если
+//	                         запуск не начат (см. Scheduler.Trigger)
+//
+// Для размещения под префиксом используйте http.StripPrefix.
+func (s *Scheduler) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		infos := s.Jobs()
+		out := make([]jobStatus, 0, len(infos))
+		for _, info := range infos {
+			runs, err := s.History(info.Name)
+			if err != nil {
+				continue // задачу удалили между вызовами
+			}
+			out = append(out, jobStatus{JobInfo: info, History: runs})
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+	mux.HandleFunc("GET /jobs/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		info, ok := s.job(name)
+		if !ok {
+			writeError(w, http.StatusNotFound, ErrUnknownJob)
+			return
+		}
+		runs, err := s.History(name)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, jobStatus{JobInfo: info, History: runs})
+	})
+	mux.HandleFunc("POST /jobs/{name}/run", func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Trigger(r.PathValue("name")); err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, ErrUnknownJob):
+				status = http.StatusNotFound
+			case errors.Is(err, ErrRejected):
+				status = http.StatusConflict
+			}
+			writeError(w, status, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "triggered"})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
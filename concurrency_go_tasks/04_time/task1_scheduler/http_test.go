@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	s := New()
+	var runs int32
+	s.Add("report", "0 3 * * *", func() { atomic.AddInt32(&runs, 1) })
+	s.Add("cleanup", "@hourly", func() {})
+	srv := httptest.NewServer(http.StripPrefix("/admin", s.Handler()))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/admin/jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []jobStatus
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list) != 2 || list[0].Name != "cleanup" || list[1].Spec != "0 3 * * *" {
+		t.Fatalf("неожиданный список задач: %+v", list)
+	}
+
+	resp, err = http.Post(srv.URL+"/admin/jobs/report/run", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("POST run: ожидался 202, получено %d", resp.StatusCode)
+	}
+	waitHistory(t, s, "report", 1)
+	if atomic.LoadInt32(&runs) != 1 {
+		t.Errorf("ручной запуск должен выполнить задачу, запусков %d", atomic.LoadInt32(&runs))
+	}
+
+	resp, err = http.Get(srv.URL + "/admin/jobs/report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var one jobStatus
+	json.NewDecoder(resp.Body).Decode(&one)
+	resp.Body.Close()
+	if one.Name != "report" || len(one.History) != 1 || one.History[0].Outcome != OutcomeOK {
+		t.Errorf("неожиданное состояние задачи: %+v", one)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/admin/jobs/missing", http.StatusNotFound},
+		{http.MethodPost, "/admin/jobs/missing/run", http.StatusNotFound},
+		{http.MethodGet, "/admin/jobs/report/run", http.StatusMethodNotAllowed},
+	} {
+		req, _ := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s: ожидался %d, получено %d", tc.method, tc.path, tc.status, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandlerRunRejected(t *testing.T) {
+	t.Parallel()
+	s := New()
+	release := make(chan struct{})
+	s.AddJob("slow", "@yearly", func(context.Context) error {
+		<-release
+		return nil
+	})
+	s.Add("jittered", "@yearly", func() {}, WithJitter(time.Hour))
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	defer close(release)
+
+	post := func(name string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/jobs/"+name+"/run", "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("slow"); code != http.StatusAccepted {
+		t.Fatalf("первый запуск: ожидался 202, получено %d", code)
+	}
+	if code := post("slow"); code != http.StatusConflict {
+		t.Errorf("запуск во время предыдущего: ожидался 409, получено %d", code)
+	}
+
+	// Ручной запуск не ждёт случайной задержки WithJitter.
+	if code := post("jittered"); code != http.StatusAccepted {
+		t.Fatalf("ожидался 202, получено %d", code)
+	}
+	waitHistory(t, s, "jittered", 1)
+
+	s.Remove("slow")
+	if code := post("slow"); code != http.StatusNotFound {
+		t.Errorf("удалённая задача: ожидался 404, получено %d", code)
+	}
+}
@@ -0,0 +1,349 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrDuplicateJob = errors.New("scheduler: job already exists") // имя задачи уже занято
+var ErrUnknownJob = errors.New("scheduler: unknown job")          // задачи с таким именем нет
+
+// SchedulerOption настраивает Scheduler при создании.
+type SchedulerOption func(*Scheduler)
+
+// WithLocation задаёт часовой пояс для выражений без CRON_TZ.
+// По умолчанию — time.Local.
+func WithLocation(loc *time.Location) SchedulerOption {
+	return func(s *Scheduler) {
+		s.loc = loc
+	}
+}
+
+// JobInfo описывает зарегистрированную задачу.
+type JobInfo struct {
+	Name string    `json:"name"`
+	Spec string    `json:"spec"`
+	Next time.Time `json:"next"` // нулевое, если запусков больше не будет
+}
+
+// Scheduler запускает именованные задачи по расписаниям cron. Задачи
+// можно добавлять и удалять в любой момент, в том числе после Start.
+// Запуски выполняются в отдельных горутинах с учётом политики
+// перекрытия задачи.
+type Scheduler struct {
+	mu      sync.Mutex
+	loc     *time.Location
+	jobs    map[string]*entry
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	now     func() time.Time
+
+	store    Store
+	lastRuns map[string]time.Time // загружается из store при первом Add
+	locker   Locker
+
+	historySize int
+}
+
+type entry struct {
+	name  string
+	spec  string
+	sched Schedule
+	run   *runner
+	next  time.Time
+
+	// missed — пропущенные при простое запуски, которые нужно
+	// выполнить после Start по политике WithMisfire.
+	missed []time.Time
+}
+
+// New создаёт планировщик. Задачи начинают выполняться после Start.
+func New(opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		loc:  time.Local,
+		jobs: make(map[string]*entry),
+		wake: make(chan struct{}, 1),
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add регистрирует задачу name с расписанием spec в формате ParseCron.
+// Перекрытие запусков настраивается опциями, как в Every.
+func (s *Scheduler) Add(name, spec string, f func(), opts ...Option) error {
+	job := func(context.Context) error {
+		f()
+		return nil
+	}
+	return s.AddJob(name, spec, job, opts...)
+}
+
+// AddJob регистрирует задачу с контекстом. Контекст отменяется при
+// Remove и Shutdown.
+func (s *Scheduler) AddJob(name, spec string, job Job, opts ...Option) error {
+	sched, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	return s.AddSchedule(name, spec, sched, job, opts...)
+}
+
+// AddSchedule регистрирует задачу с произвольным расписанием; spec
+// используется только для описания в Jobs.
+func (s *Scheduler) AddSchedule(name, spec string, sched Schedule, job Job, opts ...Option) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+	if s.store != nil && s.lastRuns == nil {
+		last, err := s.store.Load()
+		if err != nil {
+			return fmt.Errorf("scheduler: load state: %w", err)
+		}
+		s.lastRuns = last
+	}
+	cfg := newConfig(opts)
+	if cfg.locker == nil && s.locker != nil {
+		cfg.locker, cfg.lockName = s.locker, name
+	}
+	now := s.now().In(s.loc)
+	r := newRunner(context.Background(), job, cfg)
+	r.hist = newHistory(s.historySize)
+	r.nextRun = sched.Next
+	if s.store != nil {
+		r.saved = s.lastRuns[name]
+		r.save = func(at time.Time) error {
+			if err := s.store.Save(name, at); err != nil {
+				return fmt.Errorf("scheduler: save state of %s: %w", name, err)
+			}
+			return nil
+		}
+	}
+	s.jobs[name] = &entry{
+		name:   name,
+		spec:   spec,
+		sched:  sched,
+		run:    r,
+		next:   sched.Next(now),
+		missed: missedRuns(sched, s.lastRuns[name], now, cfg.misfire),
+	}
+	s.notify()
+	return nil
+}
+
+// Remove удаляет задачу. Контекст уже начатого запуска отменяется, а
+// отложенный политикой QueueOne запуск не выполняется.
+func (s *Scheduler) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	j.run.stop()
+	delete(s.jobs, name)
+	s.notify()
+	return nil
+}
+
+// Jobs возвращает задачи, отсортированные по имени.
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, JobInfo{Name: j.name, Spec: j.spec, Next: j.next})
+	}
+	sort.Slice(infos, func(i, k int) bool { return infos[i].Name < infos[k].Name })
+	return infos
+}
+
+func (s *Scheduler) job(name string) (JobInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return JobInfo{}, false
+	}
+	return JobInfo{Name: j.name, Spec: j.spec, Next: j.next}, true
+}
+
+// History возвращает последние запуски задачи name от старых к новым,
+// включая пропущенные тики.
+func (s *Scheduler) History(name string) ([]Run, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return j.run.hist.list(), nil
+}
+
+// Trigger запускает задачу name вне расписания. Запуск выполняется
+// асинхронно и сразу, без WithJitter. Если предыдущий запуск ещё идёт
+// (с учётом политики перекрытия), блокировку задачи держит другая
+// реплика или задача остановлена, запуск не начинается и возвращается
+// ErrRejected. Время ручного запуска в Store не сохраняется.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return j.run.trigger()
+}
+
+// Next возвращает до n ближайших моментов запуска задачи name.
+func (s *Scheduler) Next(name string, n int) ([]time.Time, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	now := s.now().In(s.loc)
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	times := make([]time.Time, 0, n)
+	for t := now; len(times) < n; {
+		if t = j.sched.Next(t); t.IsZero() {
+			break
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// notify будит цикл планировщика, чтобы он пересчитал ближайший запуск.
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start запускает цикл планировщика. Повторный вызов до Stop ничего
+// не делает.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		return
+	}
+	s.done = make(chan struct{})
+	s.stopped = make(chan struct{})
+	go s.loop(s.done, s.stopped)
+}
+
+// Stop останавливает цикл планировщика. Уже начатые запуски не
+// прерываются и не ожидаются; после Start расписание продолжится.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	done, stopped := s.done, s.stopped
+	s.done, s.stopped = nil, nil
+	s.mu.Unlock()
+	if done == nil {
+		return
+	}
+	close(done)
+	<-stopped
+}
+
+// Shutdown останавливает цикл планировщика, отменяет контексты всех
+// задач и ждёт завершения выполняющихся запусков, но не дольше, чем
+// живёт ctx. После Shutdown планировщик не перезапускается.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.Stop()
+	s.mu.Lock()
+	runs := make([]*runner, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.run.stop()
+		runs = append(runs, j.run)
+	}
+	s.mu.Unlock()
+	for _, r := range runs {
+		if err := r.wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) loop(done, stopped chan struct{}) {
+	defer close(stopped)
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		var wait <-chan time.Time
+		if d, ok := s.runDue(); ok {
+			timer.Reset(d)
+			wait = timer.C
+		}
+		select {
+		case <-wait:
+		case <-s.wake:
+			timer.Stop()
+		case <-done:
+			return
+		}
+	}
+}
+
+// runDue запускает задачи, время которых наступило, и возвращает
+// задержку до ближайшего следующего запуска.
+func (s *Scheduler) runDue() (time.Duration, bool) {
+	type due struct {
+		run    *runner
+		at     time.Time
+		missed []time.Time // непусто — навёрстывание пропущенных запусков
+	}
+	var runs []due
+
+	s.mu.Lock()
+	now := s.now().In(s.loc)
+	var earliest time.Time
+	for _, j := range s.jobs {
+		if len(j.missed) > 0 {
+			runs = append(runs, due{run: j.run, missed: j.missed})
+			j.missed = nil
+		}
+		if j.next.IsZero() {
+			continue
+		}
+		if !j.next.After(now) {
+			runs = append(runs, due{run: j.run, at: j.next})
+			j.next = j.sched.Next(now)
+			if j.next.IsZero() {
+				continue
+			}
+		}
+		if earliest.IsZero() || j.next.Before(earliest) {
+			earliest = j.next
+		}
+	}
+	s.mu.Unlock()
+
+	// Запускаем вне s.mu: обработчики пропуска могут обращаться к
+	// планировщику. Время запуска сохраняется в Store самим запуском
+	// после успешного выполнения задачи.
+	for _, r := range runs {
+		if len(r.missed) > 0 {
+			r.run.catchUp(r.missed)
+		} else {
+			r.run.tick(r.at)
+		}
+	}
+	if earliest.IsZero() {
+		return 0, false
+	}
+	return earliest.Sub(now), true
+}
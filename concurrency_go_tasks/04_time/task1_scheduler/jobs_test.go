@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerJobsRegistry(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC)
+	s := New(WithLocation(time.UTC))
+	s.now = func() time.Time { return now }
+
+	if err := s.Add("nightly", "0 3 * * *", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("business", "0 9-18 * * mon-fri", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("nightly", "@daily", func() {}); !errors.Is(err, ErrDuplicateJob) {
+		t.Errorf("ожидалась ErrDuplicateJob, получено %v", err)
+	}
+	if err := s.Add("bad", "* *", func() {}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("ожидалась ErrBadSpec, получено %v", err)
+	}
+
+	jobs := s.Jobs()
+	if len(jobs) != 2 || jobs[0].Name != "business" || jobs[1].Name != "nightly" {
+		t.Fatalf("неожиданный список задач: %+v", jobs)
+	}
+	if want := time.Date(2024, 3, 11, 3, 0, 0, 0, time.UTC); !jobs[1].Next.Equal(want) {
+		t.Errorf("nightly: ожидался запуск %s, получено %s", want, jobs[1].Next)
+	}
+
+	next, err := s.Next("business", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, h := range []int{9, 10, 11} {
+		if want := time.Date(2024, 3, 11, h, 0, 0, 0, time.UTC); !next[i].Equal(want) {
+			t.Errorf("business[%d]: ожидалось %s, получено %s", i, want, next[i])
+		}
+	}
+
+	if err := s.Remove("nightly"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove("nightly"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("ожидалась ErrUnknownJob, получено %v", err)
+	}
+	if _, err := s.Next("nightly", 1); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("ожидалась ErrUnknownJob, получено %v", err)
+	}
+}
+
+func TestSchedulerRunsAndRemoves(t *testing.T) {
+	t.Parallel()
+	s := New()
+	var fast, slow int32
+	s.Add("fast", "@every 20ms", func() { atomic.AddInt32(&fast, 1) })
+	s.Start()
+	defer s.Stop()
+
+	// Задача, добавленная после Start, тоже подхватывается.
+	s.Add("slow", "@every 50ms", func() { atomic.AddInt32(&slow, 1) })
+	time.Sleep(130 * time.Millisecond)
+	if err := s.Remove("fast"); err != nil {
+		t.Fatal(err)
+	}
+	f := atomic.LoadInt32(&fast)
+	if f < 4 || f > 7 {
+		t.Errorf("fast: ожидалось 4-7 запусков за 130мс, получено %d", f)
+	}
+	if c := atomic.LoadInt32(&slow); c < 1 || c > 3 {
+		t.Errorf("slow: ожидалось 1-3 запуска, получено %d", c)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if atomic.LoadInt32(&fast) != f {
+		t.Error("удалённая задача не должна запускаться")
+	}
+}
+
+func TestSchedulerStop(t *testing.T) {
+	t.Parallel()
+	s := New()
+	var count int32
+	s.Add("job", "@every 10ms", func() { atomic.AddInt32(&count, 1) })
+	s.Start()
+	s.Start()
+	time.Sleep(35 * time.Millisecond)
+	s.Stop()
+	s.Stop()
+	c := atomic.LoadInt32(&count)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&count) != c {
+		t.Error("после Stop задачи не должны запускаться")
+	}
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"sync"
+)
+
+var ErrLockUnsupported = errors.New("scheduler: file locks are not supported on this platform") // FileLocker недоступен
+
+// Locker — распределённая блокировка, через которую реплики
+// договариваются, кто выполняет очередной запуск задачи.
+type Locker interface {
+	// TryLock пытается захватить блокировку name без ожидания. Если её
+	// держит другой владелец, возвращается ok == false. Захваченная
+	// блокировка освобождается вызовом unlock.
+	TryLock(ctx context.Context, name string) (unlock func() error, ok bool, err error)
+}
+
+// WithLock выполняет запуски задачи, только захватив блокировку name в
+// l; если её держит другая реплика, запуск пропускается и передаётся в
+// WithOnSkip. Блокировка удерживается до следующего тика по расписанию,
+// а не только на время запуска: реплики, запущенные в разное время,
+// тикают со сдвигом, и короткая блокировка досталась бы каждой по
+// очереди. Так задача выполняется примерно один раз за интервал, какая
+// бы реплика её ни захватила.
+func WithLock(l Locker, name string) Option {
+	return func(c *config) {
+		c.locker = l
+		c.lockName = name
+	}
+}
+
+// WithLocker задаёт блокировку для всех задач Scheduler; имя
+// блокировки совпадает с именем задачи. WithLock у задачи имеет
+// приоритет.
+func WithLocker(l Locker) SchedulerOption {
+	return func(s *Scheduler) {
+		s.locker = l
+	}
+}
+
+// MemoryLocker — Locker в памяти процесса, для тестов и для
+// нескольких планировщиков в одном процессе.
+type MemoryLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+var _ Locker = (*MemoryLocker)(nil)
+
+// NewMemoryLocker создаёт пустой MemoryLocker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{held: make(map[string]bool)}
+}
+
+// TryLock захватывает блокировку name, если она свободна.
+func (m *MemoryLocker) TryLock(_ context.Context, name string) (func() error, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.held[name] {
+		return nil, false, nil
+	}
+	m.held[name] = true
+	var once sync.Once
+	return func() error {
+		once.Do(func() {
+			m.mu.Lock()
+			delete(m.held, name)
+			m.mu.Unlock()
+		})
+		return nil
+	}, true, nil
+}
+
+// FileLocker — Locker на файловых блокировках (flock) в каталоге dir.
+// Подходит для реплик на одной машине или на общем томе, который
+// поддерживает flock. Работает только на unix-системах; на остальных
+// TryLock возвращает ErrLockUnsupported.
+type FileLocker struct {
+	dir string
+}
+
+var _ Locker = (*FileLocker)(nil)
+
+// NewFileLocker создаёт блокировки в каталоге dir, который должен
+// существовать.
+func NewFileLocker(dir string) *FileLocker {
+	return &FileLocker{dir: dir}
+}
+
+func (l *FileLocker) path(name string) string {
+	return filepath.Join(l.dir, url.PathEscape(name)+".lock")
+}
@@ -0,0 +1,268 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryLocker(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	ctx := context.Background()
+	unlock, ok, err := l.TryLock(ctx, "job")
+	if !ok || err != nil {
+		t.Fatalf("первый захват должен удаться: %v, %v", ok, err)
+	}
+	if _, ok, _ := l.TryLock(ctx, "job"); ok {
+		t.Error("занятая блокировка не должна захватываться повторно")
+	}
+	if _, ok, _ := l.TryLock(ctx, "other"); !ok {
+		t.Error("блокировки с разными именами независимы")
+	}
+	unlock()
+	unlock()
+	if _, ok, _ := l.TryLock(ctx, "job"); !ok {
+		t.Error("после unlock блокировка должна освободиться")
+	}
+}
+
+func TestEveryWithLockRunsOneReplica(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	f, runs, peak := trackRuns(10 * time.Millisecond)
+	var skipped int32
+	var stops []func()
+	for i := 0; i < 3; i++ {
+		stops = append(stops, Every(20*time.Millisecond, f,
+			WithLock(l, "health-sync"),
+			WithOnSkip(func(time.Time) { atomic.AddInt32(&skipped, 1) }),
+		))
+	}
+	time.Sleep(110 * time.Millisecond)
+	for _, stop := range stops {
+		stop()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if p := atomic.LoadInt32(peak); p != 1 {
+		t.Errorf("одновременно должна работать одна реплика, работало %d", p)
+	}
+	if r, s := atomic.LoadInt32(runs), atomic.LoadInt32(&skipped); r == 0 || s < r {
+		t.Errorf("ожидалось, что большинство тиков реплик пропущено: запусков %d, пропусков %d", r, s)
+	}
+}
+
+func TestEveryWithLockOffsetReplicas(t *testing.T) {
+	t.Parallel()
+	const (
+		interval  = 60 * time.Millisecond
+		intervals = 10
+	)
+	l := NewMemoryLocker()
+	var runs int32
+	f := func() { atomic.AddInt32(&runs, 1) }
+	var stops []func()
+	// Реплики стартуют со сдвигом, поэтому их тики не совпадают.
+	for i := 0; i < 3; i++ {
+		stops = append(stops, Every(interval, f, WithLock(l, "billing")))
+		time.Sleep(interval / 3)
+	}
+	time.Sleep(intervals * interval)
+	for _, stop := range stops {
+		stop()
+	}
+
+	// Первая реплика успевает сделать ещё пару тиков, пока стартуют
+	// остальные.
+	if r := atomic.LoadInt32(&runs); r < intervals/2 || r > intervals+3 {
+		t.Errorf("ожидался примерно один запуск за интервал (%d), получено %d", intervals, r)
+	}
+}
+
+func TestEveryWithLockSingleReplica(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	var runs, skipped int32
+	stop := Every(10*time.Millisecond, func() { atomic.AddInt32(&runs, 1) },
+		WithLock(l, "job"),
+		WithOnSkip(func(time.Time) { atomic.AddInt32(&skipped, 1) }),
+	)
+	time.Sleep(105 * time.Millisecond)
+	stop()
+	if s := atomic.LoadInt32(&skipped); s != 0 {
+		t.Errorf("единственная реплика не должна пропускать свои тики из-за аренды, пропущено %d из %d", s, atomic.LoadInt32(&runs)+s)
+	}
+}
+
+type failingLocker struct{ err error }
+
+func (l failingLocker) TryLock(context.Context, string) (func() error, bool, error) {
+	return nil, false, l.err
+}
+
+func TestLockErrorIsReported(t *testing.T) {
+	t.Parallel()
+	boom := errors.New("lock backend down")
+	var ran atomic.Bool
+	errs := make(chan error, 10)
+	r := newRunner(context.Background(), plain(func() { ran.Store(true) }), newConfig([]Option{
+		WithLock(failingLocker{boom}, "job"),
+		WithOnError(func(err error) { errs <- err }),
+	}))
+	r.runNow(time.Now())
+	if ran.Load() {
+		t.Error("без блокировки задача не должна выполняться")
+	}
+	if err := <-errs; !errors.Is(err, boom) {
+		t.Errorf("ожидалась ошибка блокировки, получено %v", err)
+	}
+}
+
+func TestSchedulerWithLocker(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	f, runs, peak := trackRuns(15 * time.Millisecond)
+	var replicas []*Scheduler
+	for i := 0; i < 3; i++ {
+		s := New(WithLocker(l))
+		s.Add("report", "@every 20ms", f)
+		s.Start()
+		replicas = append(replicas, s)
+	}
+	time.Sleep(100 * time.Millisecond)
+	for _, s := range replicas {
+		s.Shutdown(context.Background())
+	}
+	if p := atomic.LoadInt32(peak); p != 1 {
+		t.Errorf("задача должна выполняться на одной реплике за раз, одновременно %d", p)
+	}
+	if atomic.LoadInt32(runs) == 0 {
+		t.Error("задача должна была выполниться")
+	}
+}
+
+func TestTriggerRejectedByLock(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	unlock, _, _ := l.TryLock(context.Background(), "report")
+	defer unlock()
+	s := New(WithLocker(l))
+	var runs int32
+	s.Add("report", "@yearly", func() { atomic.AddInt32(&runs, 1) })
+	if err := s.Trigger("report"); !errors.Is(err, ErrRejected) {
+		t.Fatalf("ожидалась ErrRejected, получено %v", err)
+	}
+	unlock()
+	if err := s.Trigger("report"); err != nil {
+		t.Fatalf("после освобождения блокировки запуск должен приниматься: %v", err)
+	}
+	waitHistory(t, s, "report", 2)
+	if atomic.LoadInt32(&runs) != 1 {
+		t.Errorf("ожидался один запуск, получено %d", atomic.LoadInt32(&runs))
+	}
+}
+
+func TestTriggerWhileOwnRunHoldsLock(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	s := New(WithLocker(l))
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	s.Add("report", "@every 20ms", func() {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	})
+	s.Start()
+	<-started
+	s.Stop()
+	defer close(release)
+	before, _ := s.History("report")
+
+	err := s.Trigger("report")
+	if !errors.Is(err, ErrRejected) || !strings.Contains(err.Error(), "still in progress") {
+		t.Fatalf("ожидался отказ из-за идущего запуска, получено %v", err)
+	}
+	after, _ := s.History("report")
+	if n := len(after) - len(before); n != 1 {
+		t.Errorf("отказ должен записываться в историю один раз, записей %d", n)
+	}
+}
+
+func TestTriggerKeepsLeaseWhenBusy(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	r := newRunner(context.Background(), plain(func() {}), newConfig([]Option{
+		WithLock(l, "job"),
+	}))
+	r.nextRun = func(at time.Time) time.Time { return at.Add(time.Hour) }
+	r.runNow(time.Now())
+
+	r.mu.Lock()
+	r.running = 1 // запуск ещё идёт
+	r.mu.Unlock()
+	if err := r.trigger(); !errors.Is(err, ErrRejected) {
+		t.Fatalf("ожидалась ErrRejected, получено %v", err)
+	}
+	if unlock, ok, _ := l.TryLock(context.Background(), "job"); ok {
+		unlock()
+		t.Fatal("отклонённый ручной запуск не должен снимать аренду")
+	}
+
+	r.mu.Lock()
+	r.running = 0
+	r.mu.Unlock()
+	if err := r.trigger(); err != nil {
+		t.Fatalf("ручной запуск должен забирать свою аренду: %v", err)
+	}
+	r.wg.Wait()
+	unlock, ok, _ := l.TryLock(context.Background(), "job")
+	if !ok {
+		t.Fatal("после ручного запуска блокировка должна освобождаться")
+	}
+	unlock()
+}
+
+func TestStopReleasesLease(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	ran := make(chan struct{}, 1)
+	task := EveryContext(context.Background(), time.Hour, plain(func() { ran <- struct{}{} }),
+		WithLock(l, "job"), WithImmediate())
+	<-ran
+	if unlock, ok, _ := l.TryLock(context.Background(), "job"); ok {
+		unlock()
+		t.Fatal("до остановки блокировка должна удерживаться до следующего тика")
+	}
+	if err := task.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	unlock, ok, _ := l.TryLock(context.Background(), "job")
+	if !ok {
+		t.Fatal("после Shutdown блокировка должна освобождаться")
+	}
+	unlock()
+}
+
+func TestSchedulerShutdownReleasesLease(t *testing.T) {
+	t.Parallel()
+	l := NewMemoryLocker()
+	s := New(WithLocker(l))
+	s.Add("report", "@every 50ms", func() {})
+	s.Start()
+	waitHistory(t, s, "report", 1)
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	unlock, ok, _ := l.TryLock(context.Background(), "report")
+	if !ok {
+		t.Fatal("после Shutdown блокировка не должна ждать следующего тика")
+	}
+	unlock()
+}
@@ -0,0 +1,424 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrRejected = errors.New("scheduler: run rejected") // ручной запуск не начат: задача остановлена, занята или заблокирована другой репликой
+
+// Overlap определяет, что делать с тиком, наступившим во время
+// выполнения предыдущего запуска.
+type Overlap int
+
+const (
+	// QueueOne — отложить один запуск до завершения текущего, остальные
+	// тики пропустить (по умолчанию).
+	QueueOne Overlap = iota
+	// Skip — пропустить тик.
+	Skip
+	// Concurrent — запускать параллельно, не более WithMaxConcurrent
+	// запусков одновременно; тики сверх лимита пропускаются.
+	Concurrent
+)
+
+// Option настраивает выполнение задачи в Every и Scheduler.Add.
+type Option func(*config)
+
+type config struct {
+	overlap         Overlap
+	maxConcurrent   int
+	onSkip          func(at time.Time)
+	mode            Mode
+	jitter          time.Duration
+	initialDelay    time.Duration
+	hasInitialDelay bool
+	onError         func(err error)
+	misfire         Misfire
+	locker          Locker
+	lockName        string
+}
+
+func newConfig(opts []Option) config {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// WithOverlap задаёт политику перекрытия запусков.
+func WithOverlap(p Overlap) Option {
+	return func(c *config) {
+		c.overlap = p
+	}
+}
+
+// WithMaxConcurrent ограничивает число одновременных запусков при
+// политике Concurrent. 0 — без ограничения.
+func WithMaxConcurrent(n int) Option {
+	return func(c *config) {
+		c.maxConcurrent = n
+	}
+}
+
+// WithOnSkip задаёт функцию, вызываемую с моментом каждого пропущенного
+// тика.
+func WithOnSkip(f func(at time.Time)) Option {
+	return func(c *config) {
+		c.onSkip = f
+	}
+}
+
+// runner применяет политику перекрытия к запускам одной задачи.
+type runner struct {
+	cfg    config
+	job    Job
+	ctx    context.Context // отменяется в stop; передаётся в job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup // выполняющиеся запуски и цикл Every
+
+	mu        sync.Mutex
+	running   int
+	pending   bool
+	pendingAt time.Time // момент тика отложенного запуска
+
+	hist *history // nil, если история не ведётся
+
+	// nextRun возвращает следующий после at момент запуска по расписанию;
+	// до него удерживается блокировка WithLock. nil или нулевое время —
+	// блокировка освобождается сразу после запуска.
+	nextRun func(at time.Time) time.Time
+	leaseMu sync.Mutex
+	lease   *lease // блокировка, удерживаемая до следующего тика
+
+	// save запоминает момент успешно выполненного запуска; nil, если
+	// состояние не сохраняется. saved — последний сохранённый момент.
+	save   func(at time.Time) error
+	saveMu sync.Mutex
+	saved  time.Time
+}
+
+func newRunner(parent context.Context, job Job, cfg config) *runner {
+	ctx, cancel := context.WithCancel(parent)
+	return &runner{cfg: cfg, job: job, ctx: ctx, cancel: cancel}
+}
+
+// stopped сообщает, что новые запуски запрещены. Задача также
+// останавливается при отмене родительского контекста.
+func (r *runner) stopped() bool {
+	return r.ctx.Err() != nil
+}
+
+func (r *runner) limit() int {
+	if r.cfg.overlap == Concurrent {
+		return r.cfg.maxConcurrent
+	}
+	return 1
+}
+
+// tick обрабатывает наступление времени запуска at.
+func (r *runner) tick(at time.Time) {
+	r.mu.Lock()
+	if r.stopped() {
+		r.mu.Unlock()
+		return
+	}
+	if limit := r.limit(); limit <= 0 || r.running < limit {
+		r.running++
+		r.wg.Add(1)
+		r.mu.Unlock()
+		go r.run(func() { r.runNow(at) })
+		return
+	}
+	if r.cfg.overlap == QueueOne && !r.pending {
+		r.pending, r.pendingAt = true, at
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+	r.skip(at)
+}
+
+func (r *runner) skip(at time.Time) {
+	if r.hist != nil {
+		r.hist.add(Run{Start: at, Outcome: OutcomeSkipped})
+	}
+	if r.cfg.onSkip != nil {
+		r.cfg.onSkip(at)
+	}
+}
+
+// catchUp выполняет пропущенные запуски missed по очереди в одной
+// горутине. На это время задача считается выполняющейся, поэтому
+// регулярные тики подчиняются политике перекрытия.
+func (r *runner) catchUp(missed []time.Time) {
+	r.mu.Lock()
+	if r.stopped() {
+		r.mu.Unlock()
+		return
+	}
+	r.running++
+	r.wg.Add(1)
+	r.mu.Unlock()
+	go func() {
+		last := len(missed) - 1
+		for i := 0; i < last && !r.stopped(); i++ {
+			r.runNow(missed[i])
+		}
+		r.run(func() { r.runNow(missed[last]) })
+	}()
+}
+
+func (r *runner) reportError(err error) {
+	if r.cfg.onError != nil {
+		r.cfg.onError(err)
+	}
+}
+
+// run выполняет запуск first и отложенный QueueOne запуск, если он
+// есть, после чего освобождает занятое место.
+func (r *runner) run(first func()) {
+	defer r.wg.Done()
+	first()
+	for {
+		r.mu.Lock()
+		if r.pending && !r.stopped() {
+			r.pending = false
+			at := r.pendingAt
+			r.mu.Unlock()
+			r.runNow(at)
+			continue
+		}
+		r.pending = false
+		r.running--
+		r.mu.Unlock()
+		return
+	}
+}
+
+// trigger начинает ручной запуск вне расписания. Он выполняется сразу,
+// без WithJitter и без очереди QueueOne, а время запуска не сохраняется.
+// Если запуск нельзя начать, возвращается ErrRejected, а занятость
+// задачи или блокировки записывается в историю как пропуск.
+func (r *runner) trigger() error {
+	r.mu.Lock()
+	if r.stopped() {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: job is stopped", ErrRejected)
+	}
+	if limit := r.limit(); limit > 0 && r.running >= limit {
+		r.mu.Unlock()
+		r.skip(time.Now())
+		return fmt.Errorf("%w: previous run is still in progress", ErrRejected)
+	}
+	r.running++
+	r.wg.Add(1)
+	r.mu.Unlock()
+
+	unlock, err := r.lockManual()
+	if err != nil {
+		r.mu.Lock()
+		pending := r.pending && !r.stopped()
+		if !pending {
+			r.running--
+		}
+		r.mu.Unlock()
+		if pending {
+			// За время попытки появился отложенный QueueOne
+			// запуск: место переходит к нему.
+			go r.run(func() {})
+		} else {
+			r.wg.Done()
+		}
+		return err
+	}
+	go r.run(func() {
+		start := time.Now()
+		err := r.job(r.ctx)
+		if unlock != nil {
+			if err := unlock(); err != nil {
+				r.reportError(err)
+			}
+		}
+		r.finish(start, err)
+	})
+	return nil
+}
+
+// lockManual захватывает блокировку WithLock для ручного запуска, место
+// для которого уже занято. Если блокировку держит аренда прошлого
+// запуска этой же задачи, аренда снимается: ручной запуск держит
+// блокировку только на время своего выполнения. Чужая блокировка
+// отклоняет запуск, а своя аренда при этом сохраняется.
+func (r *runner) lockManual() (unlock func() error, err error) {
+	if r.cfg.locker == nil {
+		return nil, nil
+	}
+	unlock, ok, err := r.cfg.locker.TryLock(r.ctx, r.cfg.lockName)
+	if err == nil && !ok && r.holdsLease() {
+		r.dropLease()
+		unlock, ok, err = r.cfg.locker.TryLock(r.ctx, r.cfg.lockName)
+	}
+	if err != nil {
+		r.finish(time.Now(), err)
+		return nil, err
+	}
+	if !ok {
+		r.skip(time.Now())
+		return nil, fmt.Errorf("%w: lock %q is held by another replica", ErrRejected, r.cfg.lockName)
+	}
+	return unlock, nil
+}
+
+// runNow выполняет запуск тика at после случайной задержки WithJitter,
+// если задачу не остановили за время ожидания и удалось захватить
+// блокировку WithLock. Ошибка передаётся в WithOnError, а момент
+// успешного запуска сохраняется.
+func (r *runner) runNow(at time.Time) {
+	if d := r.cfg.randomJitter(); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.ctx.Done():
+			return
+		}
+	}
+	if r.cfg.locker != nil {
+		r.dropLease()
+		unlock, ok, err := r.cfg.locker.TryLock(r.ctx, r.cfg.lockName)
+		if err != nil {
+			r.finish(time.Now(), err)
+			return
+		}
+		if !ok {
+			r.skip(time.Now())
+			return
+		}
+		defer r.release(unlock, at)
+	}
+	start := time.Now()
+	err := r.job(r.ctx)
+	r.finish(start, err)
+	if err == nil {
+		r.succeeded(at)
+	}
+}
+
+// release освобождает блокировку запуска тика at не раньше следующего
+// тика по расписанию. Реплики запускают задачу по своим часам и с
+// разным сдвигом сетки, поэтому блокировка работает как аренда
+// интервала: все тики других реплик внутри него пропускаются, и задача
+// выполняется один раз за интервал, даже если запуск короткий.
+// Своя аренда снимается перед следующим запуском (dropLease), чтобы
+// реплика-владелец не пропустила свой же тик, и при остановке задачи.
+func (r *runner) release(unlock func() error, at time.Time) {
+	var once sync.Once
+	free := func() {
+		once.Do(func() {
+			if err := unlock(); err != nil {
+				r.reportError(err)
+			}
+		})
+	}
+	if r.nextRun != nil {
+		if next := r.nextRun(at); !next.IsZero() {
+			if d := time.Until(next); d > 0 {
+				r.leaseMu.Lock()
+				// Запуск мог завершиться уже после stop: аренду
+				// некому будет снять.
+				if !r.stopped() {
+					r.lease = &lease{timer: time.AfterFunc(d, free), free: free}
+					r.leaseMu.Unlock()
+					return
+				}
+				r.leaseMu.Unlock()
+			}
+		}
+	}
+	free()
+}
+
+// lease — блокировка WithLock, удерживаемая после запуска до следующего
+// тика. free освобождает её ровно один раз.
+type lease struct {
+	timer *time.Timer
+	free  func()
+}
+
+// holdsLease сообщает, что задача держит аренду прошлого запуска.
+func (r *runner) holdsLease() bool {
+	r.leaseMu.Lock()
+	defer r.leaseMu.Unlock()
+	return r.lease != nil
+}
+
+// dropLease досрочно освобождает аренду, оставшуюся от прошлого запуска.
+func (r *runner) dropLease() {
+	r.leaseMu.Lock()
+	l := r.lease
+	r.lease = nil
+	r.leaseMu.Unlock()
+	if l != nil {
+		l.timer.Stop()
+		l.free() // ждёт, если таймер уже освобождает блокировку
+	}
+}
+
+// succeeded сохраняет момент тика at успешного запуска. Запуски при
+// Concurrent могут завершиться не по порядку, поэтому сохранённое время
+// назад не сдвигается.
+func (r *runner) succeeded(at time.Time) {
+	if r.save == nil {
+		return
+	}
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+	if !at.After(r.saved) {
+		return
+	}
+	if err := r.save(at); err != nil {
+		r.reportError(err)
+		return
+	}
+	r.saved = at
+}
+
+// finish записывает итог запуска в историю и передаёт ошибку в
+// WithOnError.
+func (r *runner) finish(start time.Time, err error) {
+	if r.hist != nil {
+		r.hist.recordRun(start, err)
+	}
+	if err != nil {
+		r.reportError(err)
+	}
+}
+
+// stop запрещает новые запуски, включая отложенный, отменяет контекст
+// выполняющихся и освобождает аренду блокировки WithLock, чтобы другие
+// реплики не ждали следующего тика остановленной задачи. Повторный
+// вызов безопасен.
+func (r *runner) stop() {
+	r.cancel()
+	r.dropLease()
+}
+
+// wait ждёт завершения выполняющихся запусков не дольше, чем живёт ctx.
+func (r *runner) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackRuns возвращает функцию, выполняющуюся dur, и счётчики запусков
+// и максимального числа одновременных выполнений.
+func trackRuns(dur time.Duration) (f func(), runs, peak *int32) {
+	runs, peak = new(int32), new(int32)
+	var active int32
+	f = func() {
+		atomic.AddInt32(runs, 1)
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(peak)
+			if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(dur)
+		atomic.AddInt32(&active, -1)
+	}
+	return f, runs, peak
+}
+
+// plain превращает функцию без контекста в Job.
+func plain(f func()) Job {
+	return func(context.Context) error {
+		f()
+		return nil
+	}
+}
+
+func TestRunnerSkip(t *testing.T) {
+	t.Parallel()
+	f, runs, peak := trackRuns(30 * time.Millisecond)
+	var skipped int32
+	r := newRunner(context.Background(), plain(f), newConfig([]Option{
+		WithOverlap(Skip),
+		WithOnSkip(func(time.Time) { atomic.AddInt32(&skipped, 1) }),
+	}))
+	for i := 0; i < 3; i++ {
+		r.tick(time.Now())
+	}
+	time.Sleep(60 * time.Millisecond)
+	if atomic.LoadInt32(runs) != 1 || atomic.LoadInt32(&skipped) != 2 || atomic.LoadInt32(peak) != 1 {
+		t.Errorf("ожидался 1 запуск и 2 пропуска, получено %d и %d", atomic.LoadInt32(runs), atomic.LoadInt32(&skipped))
+	}
+}
+
+func TestRunnerQueueOne(t *testing.T) {
+	t.Parallel()
+	f, runs, peak := trackRuns(20 * time.Millisecond)
+	var skipped int32
+	r := newRunner(context.Background(), plain(f), newConfig([]Option{
+		WithOnSkip(func(time.Time) { atomic.AddInt32(&skipped, 1) }),
+	}))
+	for i := 0; i < 4; i++ {
+		r.tick(time.Now())
+	}
+	time.Sleep(80 * time.Millisecond)
+	if atomic.LoadInt32(runs) != 2 || atomic.LoadInt32(&skipped) != 2 {
+		t.Errorf("ожидалось 2 запуска (текущий и отложенный) и 2 пропуска, получено %d и %d", atomic.LoadInt32(runs), atomic.LoadInt32(&skipped))
+	}
+	if atomic.LoadInt32(peak) != 1 {
+		t.Errorf("запуски не должны перекрываться, одновременно выполнялось %d", atomic.LoadInt32(peak))
+	}
+}
+
+func TestRunnerConcurrentLimit(t *testing.T) {
+	t.Parallel()
+	f, runs, peak := trackRuns(30 * time.Millisecond)
+	var skipped int32
+	r := newRunner(context.Background(), plain(f), newConfig([]Option{
+		WithOverlap(Concurrent),
+		WithMaxConcurrent(2),
+		WithOnSkip(func(time.Time) { atomic.AddInt32(&skipped, 1) }),
+	}))
+	for i := 0; i < 5; i++ {
+		r.tick(time.Now())
+	}
+	time.Sleep(60 * time.Millisecond)
+	if atomic.LoadInt32(runs) != 2 || atomic.LoadInt32(peak) != 2 || atomic.LoadInt32(&skipped) != 3 {
+		t.Errorf("ожидалось 2 параллельных запуска и 3 пропуска, получено %d (пик %d) и %d", atomic.LoadInt32(runs), atomic.LoadInt32(peak), atomic.LoadInt32(&skipped))
+	}
+}
+
+func TestRunnerStopDropsPending(t *testing.T) {
+	t.Parallel()
+	f, runs, _ := trackRuns(20 * time.Millisecond)
+	r := newRunner(context.Background(), plain(f), config{})
+	r.tick(time.Now())
+	r.tick(time.Now())
+	r.stop()
+	r.tick(time.Now())
+	time.Sleep(60 * time.Millisecond)
+	if atomic.LoadInt32(runs) != 1 {
+		t.Errorf("после stop отложенный запуск выполняться не должен, запусков %d", atomic.LoadInt32(runs))
+	}
+}
+
+func TestEverySkipReportsLongRuns(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var skips []time.Time
+	f, runs, peak := trackRuns(45 * time.Millisecond)
+	stop := Every(20*time.Millisecond, f, WithOverlap(Skip), WithOnSkip(func(at time.Time) {
+		mu.Lock()
+		skips = append(skips, at)
+		mu.Unlock()
+	}))
+	time.Sleep(130 * time.Millisecond)
+	stop()
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if atomic.LoadInt32(peak) != 1 {
+		t.Errorf("при Skip запуски не должны перекрываться, одновременно выполнялось %d", atomic.LoadInt32(peak))
+	}
+	if len(skips) == 0 || atomic.LoadInt32(runs) == 0 {
+		t.Errorf("ожидались и запуски, и пропуски, получено %d и %d", atomic.LoadInt32(runs), len(skips))
+	}
+}
@@ -1,9 +1,95 @@
 package scheduler
 
-import "time"
+import (
+	"context"
+	"time"
+)
+
+// Job — задача с контекстом. Контекст отменяется при остановке задачи.
+type Job func(ctx context.Context) error
+
+// WithOnError задаёт обработчик ошибок, возвращённых запусками задачи.
+func WithOnError(f func(err error)) Option {
+	return func(c *config) {
+		c.onError = f
+	}
+}
+
+// Task — периодическая задача, запущенная EveryContext.
+type Task struct {
+	r *runner
+}
 
 // Every запускает f каждые d и возвращает функцию для остановки.
-func Every(d time.Duration, f func()) (stop func()) {
-	// TODO: периодический вызов функции с возможностью остановки
-	return nil
+// Если f выполняется дольше d, поведение задаётся WithOverlap; момент
+// первого запуска, случайный разброс и режим отсчёта интервала
+// настраиваются WithInitialDelay, WithJitter и WithMode. Паникует, если
+// d <= 0.
+func Every(d time.Duration, f func(), opts ...Option) (stop func()) {
+	job := func(context.Context) error {
+		f()
+		return nil
+	}
+	return EveryContext(context.Background(), d, job, opts...).Stop
+}
+
+// EveryContext запускает job каждые d, как Every. Контекст запусков
+// наследуется от ctx: его отмена тоже останавливает задачу. Как и
+// time.NewTicker, паникует, если d <= 0.
+func EveryContext(ctx context.Context, d time.Duration, job Job, opts ...Option) *Task {
+	if d <= 0 {
+		panic("scheduler: non-positive interval for Every")
+	}
+	cfg := newConfig(opts)
+	r := newRunner(ctx, job, cfg)
+	r.nextRun = func(at time.Time) time.Time { return at.Add(d) }
+	first := d
+	if cfg.hasInitialDelay {
+		first = cfg.initialDelay
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		next := time.Now().Add(first)
+		timer := time.NewTimer(first)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+			case <-r.ctx.Done():
+				return
+			}
+			// Тик и остановка могли наступить одновременно: select
+			// выбирает случайно, поэтому остановку проверяем отдельно.
+			if r.stopped() {
+				return
+			}
+
+			if cfg.mode == FixedDelay {
+				r.runNow(time.Now())
+				timer.Reset(d)
+				continue
+			}
+			r.tick(next)
+			now := time.Now()
+			for next = next.Add(d); !next.After(now); next = next.Add(d) {
+				r.skip(next)
+			}
+			timer.Reset(next.Sub(now))
+		}
+	}()
+	return &Task{r: r}
+}
+
+// Stop запрещает новые запуски и отменяет контекст выполняющихся, не
+// дожидаясь их завершения. Повторный вызов безопасен.
+func (t *Task) Stop() {
+	t.r.stop()
+}
+
+// Shutdown делает то же, что Stop, и ждёт завершения выполняющихся
+// запусков. Если ctx завершится раньше, возвращается его ошибка.
+func (t *Task) Shutdown(ctx context.Context) error {
+	t.r.stop()
+	return t.r.wait(ctx)
 }
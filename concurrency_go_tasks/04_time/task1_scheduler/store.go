@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxCatchUp ограничивает число навёрстываемых запусков MisfireRunAll.
+const maxCatchUp = 1000
+
+// Misfire определяет, что делать с запусками, которые должны были
+// произойти, пока процесс не работал.
+type Misfire int
+
+const (
+	// MisfireSkip — пропустить их и ждать следующего запуска по
+	// расписанию (по умолчанию).
+	MisfireSkip Misfire = iota
+	// MisfireRunOnce — выполнить задачу один раз вместо всех пропущенных.
+	MisfireRunOnce
+	// MisfireRunAll — выполнить задачу по разу на каждый пропущенный
+	// запуск, по очереди, но не более 1000 раз.
+	MisfireRunAll
+)
+
+// WithMisfire задаёт политику для пропущенных запусков задачи
+// Scheduler. Действует, только если планировщику задан Store.
+func WithMisfire(p Misfire) Option {
+	return func(c *config) {
+		c.misfire = p
+	}
+}
+
+// Store сохраняет время последнего запуска именованных задач между
+// перезапусками процесса.
+type Store interface {
+	// Load возвращает сохранённые времена по именам задач.
+	Load() (map[string]time.Time, error)
+	// Save запоминает момент последнего успешно выполненного запуска
+	// задачи name.
+	Save(name string, last time.Time) error
+}
+
+// WithStore подключает хранилище времени запусков. Время сохраняется
+// после того, как задача выполнилась без ошибки; упавшие, пропущенные и
+// прерванные остановкой процесса запуски не сохраняются и при следующем
+// старте считаются пропущенными. При регистрации задачи пропущенные с
+// прошлого запуска процесса моменты обрабатываются по её политике
+// WithMisfire.
+func WithStore(st Store) SchedulerOption {
+	return func(s *Scheduler) {
+		s.store = st
+	}
+}
+
+// FileStore — Store в JSON-файле. Файл перезаписывается атомарно через
+// временный файл и переименование.
+type FileStore struct {
+	mu    sync.Mutex
+	path  string
+	state map[string]time.Time
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore создаёт хранилище в файле path. Файл может ещё не
+// существовать.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load читает файл. Отсутствующий файл означает пустое состояние.
+func (fs *FileStore) Load() (map[string]time.Time, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if err := fs.load(); err != nil {
+		return nil, err
+	}
+	state := make(map[string]time.Time, len(fs.state))
+	for name, t := range fs.state {
+		state[name] = t
+	}
+	return state, nil
+}
+
+func (fs *FileStore) load() error {
+	if fs.state != nil {
+		return nil
+	}
+	state := make(map[string]time.Time)
+	data, err := os.ReadFile(fs.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return err
+		}
+	}
+	fs.state = state
+	return nil
+}
+
+// Save обновляет время задачи name и перезаписывает файл.
+func (fs *FileStore) Save(name string, last time.Time) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if err := fs.load(); err != nil {
+		return err
+	}
+	fs.state[name] = last
+	data, err := json.MarshalIndent(fs.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(fs.path), filepath.Base(fs.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, fs.path)
+}
+
+// missedRuns возвращает моменты запуска после last, не позже now.
+func missedRuns(sched Schedule, last, now time.Time, p Misfire) []time.Time {
+	if p == MisfireSkip || last.IsZero() {
+		return nil
+	}
+	var missed []time.Time
+	for t := sched.Next(last); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		if p == MisfireRunOnce {
+			// Достаточно знать, что пропуск был; запоминаем последний.
+			missed = append(missed[:0], t)
+			continue
+		}
+		missed = append(missed, t)
+		if len(missed) == maxCatchUp {
+			break
+		}
+	}
+	return missed
+}
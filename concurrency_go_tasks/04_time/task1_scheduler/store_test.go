@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type memStore struct {
+	mu    sync.Mutex
+	state map[string]time.Time
+}
+
+func (m *memStore) Load() (map[string]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := make(map[string]time.Time, len(m.state))
+	for k, v := range m.state {
+		state[k] = v
+	}
+	return state, nil
+}
+
+func (m *memStore) Save(name string, last time.Time) error {
+	m.mu.Lock()
+	m.state[name] = last
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *memStore) get(name string) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state[name]
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "state.json")
+	fs := NewFileStore(path)
+	state, err := fs.Load()
+	if err != nil || len(state) != 0 {
+		t.Fatalf("отсутствующий файл — пустое состояние, получено %v, %v", state, err)
+	}
+	at := time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC)
+	if err := fs.Save("billing", at); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = NewFileStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state["billing"].Equal(at) {
+		t.Errorf("ожидалось %s, получено %s", at, state["billing"])
+	}
+	if matches, _ := filepath.Glob(path + ".tmp*"); len(matches) != 0 {
+		t.Errorf("временные файлы должны удаляться: %v", matches)
+	}
+}
+
+func TestFileStoreCorrupt(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "state.json")
+	os.WriteFile(path, []byte("{not json"), 0o644)
+	if _, err := NewFileStore(path).Load(); err == nil {
+		t.Error("ожидалась ошибка разбора повреждённого файла")
+	}
+}
+
+func TestMissedRuns(t *testing.T) {
+	t.Parallel()
+	hourly := mustParse(t, "@hourly")
+	last := time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC)
+	now := last.Add(3*time.Hour + 30*time.Minute)
+
+	if got := missedRuns(hourly, last, now, MisfireSkip); len(got) != 0 {
+		t.Errorf("MisfireSkip: ожидалось 0, получено %v", got)
+	}
+	got := missedRuns(hourly, last, now, MisfireRunOnce)
+	if len(got) != 1 || !got[0].Equal(last.Add(3*time.Hour)) {
+		t.Errorf("MisfireRunOnce: ожидался последний пропуск, получено %v", got)
+	}
+	if got := missedRuns(hourly, last, now, MisfireRunAll); len(got) != 3 {
+		t.Errorf("MisfireRunAll: ожидалось 3, получено %v", got)
+	}
+	if got := missedRuns(hourly, time.Time{}, now, MisfireRunAll); len(got) != 0 {
+		t.Errorf("без сохранённого времени пропусков нет, получено %v", got)
+	}
+	secondly := mustParse(t, "* * * * * *")
+	if got := missedRuns(secondly, last, now, MisfireRunAll); len(got) != maxCatchUp {
+		t.Errorf("навёрстывание должно ограничиваться %d, получено %d", maxCatchUp, len(got))
+	}
+}
+
+func TestSchedulerMisfire(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 3, 10, 6, 30, 0, 0, time.UTC)
+	last := time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy Misfire
+		want   int32
+	}{
+		{MisfireSkip, 0},
+		{MisfireRunOnce, 1},
+		{MisfireRunAll, 3},
+	}
+	for _, tt := range tests {
+		st := &memStore{state: map[string]time.Time{"billing": last}}
+		s := New(WithStore(st), WithLocation(time.UTC))
+		s.now = func() time.Time { return now }
+		var runs int32
+		if err := s.Add("billing", "@hourly", func() { atomic.AddInt32(&runs, 1) }, WithMisfire(tt.policy)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if atomic.LoadInt32(&runs) != 0 {
+			t.Fatal("навёрстывание должно начинаться только после Start")
+		}
+		s.Start()
+		time.Sleep(30 * time.Millisecond)
+		s.Stop()
+		if got := atomic.LoadInt32(&runs); got != tt.want {
+			t.Errorf("политика %d: ожидалось %d запусков, получено %d", tt.policy, tt.want, got)
+		}
+		if tt.want > 0 {
+			if want := time.Date(2024, 3, 10, 6, 0, 0, 0, time.UTC); !st.get("billing").Equal(want) {
+				t.Errorf("политика %d: сохранено %s, ожидалось %s", tt.policy, st.get("billing"), want)
+			}
+		}
+	}
+}
+
+func TestSchedulerSavesLastRun(t *testing.T) {
+	t.Parallel()
+	st := &memStore{state: map[string]time.Time{}}
+	s := New(WithStore(st))
+	done := make(chan struct{}, 1)
+	s.Add("sync", "@every 10ms", func() {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+	})
+	s.Start()
+	defer s.Stop()
+	<-done
+	time.Sleep(5 * time.Millisecond)
+	if st.get("sync").IsZero() {
+		t.Error("время запуска должно сохраняться в Store")
+	}
+}
+
+func TestSchedulerDoesNotSaveUnfinishedRuns(t *testing.T) {
+	t.Parallel()
+	held := NewMemoryLocker()
+	unlock, _, _ := held.TryLock(context.Background(), "locked")
+	defer unlock()
+
+	st := &memStore{state: map[string]time.Time{}}
+	s := New(WithStore(st))
+	var failed, ok int32
+	s.AddJob("failing", "@every 10ms", func(context.Context) error {
+		atomic.AddInt32(&failed, 1)
+		return errors.New("billing backend down")
+	})
+	s.Add("locked", "@every 10ms", func() {}, WithLock(held, "locked"))
+	s.Add("ok", "@every 10ms", func() { atomic.AddInt32(&ok, 1) })
+	s.Start()
+	time.Sleep(60 * time.Millisecond)
+	s.Shutdown(context.Background())
+
+	if atomic.LoadInt32(&failed) == 0 || atomic.LoadInt32(&ok) == 0 {
+		t.Fatal("задачи должны были запускаться")
+	}
+	if at := st.get("failing"); !at.IsZero() {
+		t.Errorf("запуск с ошибкой не должен сохраняться, сохранено %s", at)
+	}
+	if at := st.get("locked"); !at.IsZero() {
+		t.Errorf("пропущенный из-за блокировки запуск не должен сохраняться, сохранено %s", at)
+	}
+	if st.get("ok").IsZero() {
+		t.Error("успешный запуск должен сохраняться")
+	}
+}
@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskShutdownCancelsAndWaits(t *testing.T) {
+	t.Parallel()
+	var finished atomic.Bool
+	started := make(chan struct{}, 1)
+	task := EveryContext(context.Background(), 10*time.Millisecond, func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond) // уборка после отмены
+		finished.Store(true)
+		return ctx.Err()
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := task.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Shutdown должен дождаться завершения выполняющегося запуска")
+	}
+}
+
+func TestTaskShutdownDeadline(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	var once sync.Once
+	task := EveryContext(context.Background(), 5*time.Millisecond, func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		<-release // задача игнорирует отмену
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := task.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ожидалась DeadlineExceeded, получено %v", err)
+	}
+}
+
+func TestTaskOnError(t *testing.T) {
+	t.Parallel()
+	boom := errors.New("boom")
+	errs := make(chan error, 10)
+	var n int32
+	task := EveryContext(context.Background(), 10*time.Millisecond, func(context.Context) error {
+		if atomic.AddInt32(&n, 1)%2 == 0 {
+			return boom
+		}
+		return nil
+	}, WithOnError(func(err error) { errs <- err }))
+	time.Sleep(55 * time.Millisecond)
+	task.Stop()
+	task.Stop()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, boom) {
+			t.Errorf("ожидалась boom, получено %v", err)
+		}
+	default:
+		t.Fatal("ошибка запуска должна передаваться в WithOnError")
+	}
+}
+
+func TestTaskParentContext(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	var count int32
+	task := EveryContext(ctx, 10*time.Millisecond, func(context.Context) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	time.Sleep(25 * time.Millisecond)
+	cancel()
+	if err := task.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c := atomic.LoadInt32(&count)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&count) != c {
+		t.Error("после отмены родительского контекста задача не должна запускаться")
+	}
+}
+
+func TestSchedulerShutdown(t *testing.T) {
+	t.Parallel()
+	s := New()
+	var cancelled atomic.Bool
+	started := make(chan struct{})
+	var once sync.Once
+	s.AddJob("long", "@every 10ms", func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		cancelled.Store(true)
+		return nil
+	})
+	s.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if !cancelled.Load() {
+		t.Error("Shutdown должен отменить контекст и дождаться задачи")
+	}
+}
+
+func TestEveryRejectsNonPositiveInterval(t *testing.T) {
+	t.Parallel()
+	for _, d := range []time.Duration{0, -time.Second} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Every(%s) должен паниковать, а не крутиться в цикле пропусков", d)
+				}
+			}()
+			stop := Every(d, func() {}, WithOnSkip(func(time.Time) {}))
+			stop()
+		}()
+	}
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Mode определяет, от чего отсчитывается интервал Every.
+type Mode int
+
+const (
+	// FixedRate — запуски привязаны к сетке start+k*d независимо от
+	// длительности f (по умолчанию). Тики, пропущенные из-за задержки
+	// самого планировщика, не навёрстываются и передаются в WithOnSkip.
+	FixedRate Mode = iota
+	// FixedDelay — следующий запуск через d после завершения
+	// предыдущего. Запуски не перекрываются, WithOverlap не действует.
+	FixedDelay
+)
+
+// WithMode задаёт режим отсчёта интервала в Every.
+func WithMode(m Mode) Option {
+	return func(c *config) {
+		c.mode = m
+	}
+}
+
+// WithJitter откладывает каждый запуск на случайное время из [0, spread),
+// чтобы реплики с одинаковым расписанием не обращались к общему ресурсу
+// одновременно. Сетка FixedRate при этом не смещается.
+func WithJitter(spread time.Duration) Option {
+	return func(c *config) {
+		c.jitter = spread
+	}
+}
+
+// WithInitialDelay задаёт задержку перед первым запуском Every вместо
+// интервала d.
+func WithInitialDelay(d time.Duration) Option {
+	return func(c *config) {
+		c.initialDelay = d
+		c.hasInitialDelay = true
+	}
+}
+
+// WithImmediate запускает f в Every сразу, не дожидаясь первого
+// интервала.
+func WithImmediate() Option {
+	return WithInitialDelay(0)
+}
+
+// randomJitter возвращает случайную задержку очередного запуска.
+func (c config) randomJitter() time.Duration {
+	if c.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(c.jitter)))
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEveryImmediate(t *testing.T) {
+	t.Parallel()
+	var count int32
+	stop := Every(100*time.Millisecond, func() { atomic.AddInt32(&count, 1) }, WithImmediate())
+	time.Sleep(30 * time.Millisecond)
+	stop()
+	if c := atomic.LoadInt32(&count); c != 1 {
+		t.Errorf("WithImmediate: ожидался запуск сразу, получено %d", c)
+	}
+}
+
+func TestEveryInitialDelay(t *testing.T) {
+	t.Parallel()
+	var count int32
+	stop := Every(100*time.Millisecond, func() { atomic.AddInt32(&count, 1) }, WithInitialDelay(20*time.Millisecond))
+	time.Sleep(60 * time.Millisecond)
+	if c := atomic.LoadInt32(&count); c != 1 {
+		t.Errorf("ожидался запуск через 20мс, получено %d", c)
+	}
+	time.Sleep(100 * time.Millisecond) // второй запуск — через интервал после первого
+	stop()
+	if c := atomic.LoadInt32(&count); c != 2 {
+		t.Errorf("ожидалось 2 запуска за 160мс, получено %d", c)
+	}
+}
+
+func TestEveryFixedDelay(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var starts, ends []time.Time
+	stop := Every(20*time.Millisecond, func() {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		time.Sleep(30 * time.Millisecond)
+		mu.Lock()
+		ends = append(ends, time.Now())
+		mu.Unlock()
+	}, WithMode(FixedDelay))
+	time.Sleep(170 * time.Millisecond)
+	stop()
+	time.Sleep(40 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(starts) < 2 {
+		t.Fatalf("ожидалось хотя бы 2 запуска, получено %d", len(starts))
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(ends[i-1]); gap < 18*time.Millisecond {
+			t.Errorf("FixedDelay: запуск %d начался через %s после завершения предыдущего, ожидалось не меньше 20мс", i, gap)
+		}
+	}
+	// При FixedRate за 170мс было бы 3+ запуска с перекрытием; при
+	// FixedDelay период равен 50мс.
+	if len(starts) > 4 {
+		t.Errorf("FixedDelay: слишком много запусков: %d", len(starts))
+	}
+}
+
+func TestEveryJitter(t *testing.T) {
+	t.Parallel()
+	const replicas = 20
+	var mu sync.Mutex
+	var firsts []time.Time
+	var stops []func()
+	for i := 0; i < replicas; i++ {
+		var once sync.Once
+		stops = append(stops, Every(10*time.Millisecond, func() {
+			once.Do(func() {
+				mu.Lock()
+				firsts = append(firsts, time.Now())
+				mu.Unlock()
+			})
+		}, WithJitter(40*time.Millisecond)))
+	}
+	time.Sleep(80 * time.Millisecond)
+	for _, stop := range stops {
+		stop()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(firsts) != replicas {
+		t.Fatalf("ожидался запуск каждой реплики, получено %d", len(firsts))
+	}
+	earliest, latest := firsts[0], firsts[0]
+	for _, f := range firsts {
+		if f.Before(earliest) {
+			earliest = f
+		}
+		if f.After(latest) {
+			latest = f
+		}
+	}
+	if spread := latest.Sub(earliest); spread < 10*time.Millisecond {
+		t.Errorf("первые запуски реплик должны быть разнесены джиттером, разброс %s", spread)
+	}
+}
+
+func TestRandomJitterBounds(t *testing.T) {
+	t.Parallel()
+	c := newConfig([]Option{WithJitter(5 * time.Millisecond)})
+	for i := 0; i < 1000; i++ {
+		if d := c.randomJitter(); d < 0 || d >= 5*time.Millisecond {
+			t.Fatalf("джиттер вне [0, 5мс): %s", d)
+		}
+	}
+	if d := (config{}).randomJitter(); d != 0 {
+		t.Errorf("без WithJitter задержка должна быть 0, получено %s", d)
+	}
+}
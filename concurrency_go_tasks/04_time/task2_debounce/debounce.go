@@ -2,8 +2,131 @@ package debounce
 
 import "time"
 
+// Option настраивает Debounce и Throttle.
+type Option func(*options)
+
+type options struct {
+	leading  bool
+	trailing bool
+	maxWait  time.Duration
+}
+
+// applyOptions применяет opts к умолчаниям конкретного оператора.
+func applyOptions(o options, opts []Option) options {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithLeading включает отправку первого значения серии сразу, не
+// дожидаясь паузы (для Throttle включено по умолчанию).
+func WithLeading(on bool) Option {
+	return func(o *options) {
+		o.leading = on
+	}
+}
+
+// WithTrailing включает или отключает отправку последнего значения
+// серии после паузы (для Debounce включено по умолчанию). Вместе с
+// WithLeading(true) серия из нескольких значений даёт первое и
+// последнее, а из одного — одно. Если выключены оба края, оператор не
+// отправляет ничего и только закрывает выходной канал после in.
+func WithTrailing(on bool) Option {
+	return func(o *options) {
+		o.trailing = on
+	}
+}
+
+// WithMaxWait ограничивает задержку при непрерывном потоке: если пауза
+// не наступает за maxWait с начала серии или с предыдущей отправки,
+// последнее значение отправляется принудительно. Действует только на
+// Debounce: Throttle и так отправляет не реже раза за интервал и эту
+// опцию игнорирует.
+func WithMaxWait(maxWait time.Duration) Option {
+	return func(o *options) {
+		o.maxWait = maxWait
+	}
+}
+
 // Debounce принимает значения и отдаёт только последнее после паузы d.
-func Debounce(d time.Duration, in <-chan int) <-chan int {
-	// TODO: реализовать дебаунс значений из канала
-	return nil
+// Поведение на краях серии и ограничение задержки настраиваются
+// опциями. После закрытия in ожидающее значение отправляется по
+// окончании паузы, и выходной канал закрывается.
+func Debounce[T any](d time.Duration, in <-chan T, opts ...Option) <-chan T {
+	o := applyOptions(options{trailing: true}, opts)
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		var (
+			pending    T
+			hasPending bool
+			inBurst    bool
+		)
+		quiet := time.NewTimer(d)
+		quiet.Stop()
+		defer quiet.Stop()
+		maxWait := time.NewTimer(o.maxWait)
+		maxWait.Stop()
+		defer maxWait.Stop()
+		// Каналы таймеров равны nil, пока таймер не взведён.
+		var quietC, maxWaitC <-chan time.Time
+
+		flush := func() {
+			if hasPending {
+				out <- pending
+				var zero T
+				pending, hasPending = zero, false
+			}
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if !o.trailing || !hasPending {
+						return
+					}
+					in = nil // дожидаемся паузы и выходим
+					continue
+				}
+				if !inBurst {
+					inBurst = true
+					if o.maxWait > 0 {
+						maxWait.Reset(o.maxWait)
+						maxWaitC = maxWait.C
+					}
+					if o.leading {
+						out <- v
+						quiet.Reset(d)
+						quietC = quiet.C
+						continue
+					}
+				}
+				pending, hasPending = v, true
+				quiet.Reset(d)
+				quietC = quiet.C
+			case <-quietC:
+				if o.trailing {
+					flush()
+				}
+				var zero T
+				pending, hasPending = zero, false
+				inBurst = false
+				quietC = nil
+				maxWait.Stop()
+				maxWaitC = nil
+				if in == nil {
+					return
+				}
+			case <-maxWaitC:
+				flush()
+				if in == nil {
+					return
+				}
+				maxWait.Reset(o.maxWait)
+			}
+		}
+	}()
+	return out
 }
@@ -239,3 +239,105 @@ func BenchmarkDebounce(b *testing.B) {
 		}
 	}
 }
+
+// collect читает out до закрытия, запоминая значения и время их прихода
+// относительно start.
+func collect[T any](out <-chan T, start time.Time) ([]T, []time.Duration) {
+	var vals []T
+	var at []time.Duration
+	for v := range out {
+		vals = append(vals, v)
+		at = append(at, time.Since(start))
+	}
+	return vals, at
+}
+
+func sendEvery[T any](in chan<- T, gap time.Duration, vals ...T) {
+	for i, v := range vals {
+		if i > 0 {
+			time.Sleep(gap)
+		}
+		in <- v
+	}
+	close(in)
+}
+
+func TestDebounceGenericType(t *testing.T) {
+	t.Parallel()
+	in := make(chan string)
+	out := Debounce(10*time.Millisecond, in)
+	go sendEvery(in, time.Millisecond, "a", "b", "c")
+	if vals, _ := collect(out, time.Now()); len(vals) != 1 || vals[0] != "c" {
+		t.Fatalf("ожидалось [c], получено %v", vals)
+	}
+}
+
+func TestDebounceLeading(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	start := time.Now()
+	out := Debounce(30*time.Millisecond, in, WithLeading(true), WithTrailing(false))
+	go sendEvery(in, 5*time.Millisecond, 1, 2, 3)
+	vals, at := collect(out, start)
+	if len(vals) != 1 || vals[0] != 1 {
+		t.Fatalf("ожидалось только первое значение серии, получено %v", vals)
+	}
+	if at[0] > 10*time.Millisecond {
+		t.Errorf("первое значение должно отправляться сразу, пришло через %s", at[0])
+	}
+}
+
+func TestDebounceBothEdges(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := Debounce(20*time.Millisecond, in, WithLeading(true))
+	go func() {
+		for _, v := range []int{1, 2, 3} {
+			in <- v
+			time.Sleep(2 * time.Millisecond)
+		}
+		time.Sleep(40 * time.Millisecond)
+		in <- 4 // серия из одного значения отправляется один раз
+		close(in)
+	}()
+	vals, _ := collect(out, time.Now())
+	want := []int{1, 3, 4}
+	if len(vals) != len(want) {
+		t.Fatalf("ожидалось %v, получено %v", want, vals)
+	}
+	for i := range want {
+		if vals[i] != want[i] {
+			t.Fatalf("ожидалось %v, получено %v", want, vals)
+		}
+	}
+}
+
+func TestDebounceMaxWait(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	start := time.Now()
+	out := Debounce(20*time.Millisecond, in, WithMaxWait(50*time.Millisecond))
+	go func() {
+		// Непрерывный поток: пауза в 20мс не наступает 150мс.
+		for i := 1; i <= 30; i++ {
+			in <- i
+			time.Sleep(5 * time.Millisecond)
+		}
+		close(in)
+	}()
+	vals, at := collect(out, start)
+	if len(vals) < 3 {
+		t.Fatalf("maxWait должен принудительно отправлять значения, получено %v", vals)
+	}
+	if at[0] > 80*time.Millisecond {
+		t.Errorf("первое значение должно прийти не позже maxWait, пришло через %s", at[0])
+	}
+	if vals[len(vals)-1] != 30 {
+		t.Errorf("последним должно прийти последнее значение, получено %v", vals)
+	}
+	for i := 1; i < len(vals); i++ {
+		if vals[i] <= vals[i-1] {
+			t.Fatalf("значения должны идти по возрастанию: %v", vals)
+		}
+	}
+}
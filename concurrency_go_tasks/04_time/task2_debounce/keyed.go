@@ -0,0 +1,131 @@
+package debounce
+
+import (
+	"container/heap"
+	"time"
+)
+
+// shrinkThreshold — после скольких одновременно ожидающих ключей карта
+// и куча пересоздаются, когда ключей становится вчетверо меньше пика:
+// map в Go не отдаёт память при удалении, а срез кучи — при Pop.
+const shrinkThreshold = 1024
+
+// DebounceBy разбивает поток по ключу key и применяет Debounce к
+// каждому ключу отдельно: для ключа отправляется последнее значение
+// после паузы d в его собственных событиях. Ключ забывается сразу после
+// отправки, поэтому память занимают только ключи с ожидающим
+// значением. После закрытия in оставшиеся значения отправляются по
+// окончании их пауз, и выходной канал закрывается.
+func DebounceBy[T any, K comparable](d time.Duration, in <-chan T, key func(T) K) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		states := make(map[K]*keyState[T, K])
+		var queue keyQueue[T, K]
+		peak := 0
+
+		timer := time.NewTimer(d)
+		timer.Stop()
+		defer timer.Stop()
+		var timerC <-chan time.Time // nil, пока нет ожидающих ключей
+
+		// arm взводит таймер на ближайший срок.
+		arm := func() {
+			if len(queue) == 0 {
+				timerC = nil
+				return
+			}
+			timer.Reset(time.Until(queue[0].deadline))
+			timerC = timer.C
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if len(queue) == 0 {
+						return
+					}
+					in = nil // дожидаемся пауз оставшихся ключей
+					continue
+				}
+				k := key(v)
+				deadline := time.Now().Add(d)
+				if s, ok := states[k]; ok {
+					s.value, s.deadline = v, deadline
+					heap.Fix(&queue, s.index)
+				} else {
+					s = &keyState[T, K]{key: k, value: v, deadline: deadline}
+					states[k] = s
+					heap.Push(&queue, s)
+					if len(states) > peak {
+						peak = len(states)
+					}
+				}
+				arm()
+			case now := <-timerC:
+				for len(queue) > 0 && !queue[0].deadline.After(now) {
+					s := heap.Pop(&queue).(*keyState[T, K])
+					delete(states, s.key)
+					out <- s.value
+				}
+				states, queue, peak = shrink(states, queue, peak)
+				if in == nil && len(queue) == 0 {
+					return
+				}
+				arm()
+			}
+		}
+	}()
+	return out
+}
+
+// shrink пересоздаёт states и queue по живым ключам, если их стало
+// меньше четверти пика peak, и возвращает новый пик. Одного
+// задержавшегося ключа достаточно, чтобы карта никогда не опустела,
+// поэтому сравнивается число ключей, а не пустота.
+func shrink[T any, K comparable](states map[K]*keyState[T, K], queue keyQueue[T, K], peak int) (map[K]*keyState[T, K], keyQueue[T, K], int) {
+	if peak <= shrinkThreshold || len(states) >= peak/4 {
+		return states, queue, peak
+	}
+	fresh := make(map[K]*keyState[T, K], len(states))
+	for k, s := range states {
+		fresh[k] = s
+	}
+	// Индексы в куче сохраняются: порядок элементов не меняется.
+	return fresh, append(keyQueue[T, K](nil), queue...), len(fresh)
+}
+
+// keyState — ожидающее значение ключа.
+type keyState[T any, K comparable] struct {
+	key      K
+	value    T
+	deadline time.Time
+	index    int // позиция в keyQueue
+}
+
+// keyQueue — min-куча ключей по сроку отправки.
+type keyQueue[T any, K comparable] []*keyState[T, K]
+
+func (q keyQueue[T, K]) Len() int           { return len(q) }
+func (q keyQueue[T, K]) Less(i, j int) bool { return q[i].deadline.Before(q[j].deadline) }
+
+func (q keyQueue[T, K]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *keyQueue[T, K]) Push(x any) {
+	s := x.(*keyState[T, K])
+	s.index = len(*q)
+	*q = append(*q, s)
+}
+
+func (q *keyQueue[T, K]) Pop() any {
+	old := *q
+	s := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return s
+}
@@ -0,0 +1,163 @@
+package debounce
+
+import (
+	"container/heap"
+	"slices"
+	"testing"
+	"time"
+)
+
+type update struct {
+	Entity string
+	Rev    int
+}
+
+func TestDebounceBy(t *testing.T) {
+	t.Parallel()
+	in := make(chan update)
+	out := DebounceBy(30*time.Millisecond, in, func(u update) string { return u.Entity })
+	go func() {
+		in <- update{"a", 1}
+		in <- update{"b", 1}
+		in <- update{"a", 2}
+		time.Sleep(10 * time.Millisecond)
+		in <- update{"b", 2}
+		in <- update{"a", 3}
+		close(in)
+	}()
+	vals, _ := collect(out, time.Now())
+	want := []update{{"b", 2}, {"a", 3}}
+	if len(vals) != 2 {
+		t.Fatalf("ожидалось по одному значению на ключ %v, получено %v", want, vals)
+	}
+	// a и b обновлены почти одновременно, поэтому порядок не проверяем.
+	for _, w := range want {
+		if !slices.Contains(vals, w) {
+			t.Errorf("ожидалось последнее значение %v, получено %v", w, vals)
+		}
+	}
+}
+
+func TestDebounceByIndependentKeys(t *testing.T) {
+	t.Parallel()
+	in := make(chan update)
+	out := DebounceBy(25*time.Millisecond, in, func(u update) string { return u.Entity })
+	start := time.Now()
+	go func() {
+		in <- update{"quiet", 1}
+		// noisy обновляется непрерывно, но не задерживает quiet.
+		for i := 1; i <= 12; i++ {
+			in <- update{"noisy", i}
+			time.Sleep(5 * time.Millisecond)
+		}
+		close(in)
+	}()
+	vals, at := collect(out, start)
+	if len(vals) != 2 || vals[0] != (update{"quiet", 1}) || vals[1] != (update{"noisy", 12}) {
+		t.Fatalf("ожидалось [quiet:1 noisy:12], получено %v", vals)
+	}
+	if at[0] > 45*time.Millisecond {
+		t.Errorf("ключ quiet должен отправиться через свою паузу, пришёл через %s", at[0])
+	}
+}
+
+func TestDebounceByReclaimsKeys(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := DebounceBy(5*time.Millisecond, in, func(v int) int { return v })
+	done := make(chan []int)
+	go func() {
+		vals, _ := collect(out, time.Now())
+		done <- vals
+	}()
+	// Каждый ключ отправляется и забывается; повторное появление ключа
+	// после паузы даёт новое значение.
+	for round := 0; round < 2; round++ {
+		for k := 0; k < shrinkThreshold+10; k++ {
+			in <- k
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(in)
+	if vals := <-done; len(vals) != 2*(shrinkThreshold+10) {
+		t.Fatalf("ожидалось %d значений, получено %d", 2*(shrinkThreshold+10), len(vals))
+	}
+}
+
+func TestDebounceByLingeringKey(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := DebounceBy(10*time.Millisecond, in, func(v int) int { return v })
+	done := make(chan []int)
+	go func() {
+		vals, _ := collect(out, time.Now())
+		done <- vals
+	}()
+	// Ключ -1 всё время обновляется и не даёт карте опустеть, пока
+	// остальные ключи отправляются.
+	for k := 0; k < 2*shrinkThreshold; k++ {
+		in <- k
+		if k%64 == 0 {
+			in <- -1
+		}
+	}
+	for i := 0; i < 5; i++ {
+		time.Sleep(5 * time.Millisecond)
+		in <- -1
+	}
+	close(in)
+	vals := <-done
+	seen := make(map[int]int)
+	for _, v := range vals {
+		seen[v]++
+	}
+	for k := 0; k < 2*shrinkThreshold; k++ {
+		if seen[k] != 1 {
+			t.Fatalf("ключ %d должен отправиться один раз, отправлен %d", k, seen[k])
+		}
+	}
+	if vals[len(vals)-1] != -1 {
+		t.Errorf("задержавшийся ключ должен отправиться последним, получено %d", vals[len(vals)-1])
+	}
+}
+
+func TestShrinkByLiveKeys(t *testing.T) {
+	t.Parallel()
+	const peak = 4 * shrinkThreshold
+	states := make(map[int]*keyState[int, int])
+	var queue keyQueue[int, int]
+	for k := 0; k < peak; k++ {
+		s := &keyState[int, int]{key: k, value: k}
+		states[k] = s
+		heap.Push(&queue, s)
+	}
+	for len(queue) > shrinkThreshold {
+		delete(states, heap.Pop(&queue).(*keyState[int, int]).key)
+	}
+	if st, q, p := shrink(states, queue, peak); p != peak || len(st) != shrinkThreshold || cap(q) != cap(queue) {
+		t.Fatalf("при четверти пика пересоздавать рано, пик %d", p)
+	}
+	for len(queue) > 3 {
+		delete(states, heap.Pop(&queue).(*keyState[int, int]).key)
+	}
+	st, q, p := shrink(states, queue, peak)
+	if p != 3 || len(st) != 3 || cap(q) >= peak/4 {
+		t.Fatalf("ожидалось пересоздание по живым ключам: пик %d, ключей %d, ёмкость %d", p, len(st), cap(q))
+	}
+	for len(q) > 0 {
+		s := heap.Pop(&q).(*keyState[int, int])
+		if st[s.key] != s {
+			t.Fatalf("ключ %d потерян при пересоздании", s.key)
+		}
+	}
+}
+
+func TestDebounceByEmpty(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := DebounceBy(10*time.Millisecond, in, func(v int) int { return v })
+	close(in)
+	if _, ok := <-out; ok {
+		t.Fatal("пустой вход должен закрывать выход без значений")
+	}
+}
@@ -0,0 +1,75 @@
+package debounce
+
+import "time"
+
+// Sample каждые d отправляет последнее значение, полученное с прошлого
+// тика; если значений не было, тик пропускается. При закрытии in
+// ожидающее значение отправляется сразу. d должен быть положительным.
+func Sample[T any](d time.Duration, in <-chan T) <-chan T {
+	if d <= 0 {
+		panic("debounce: sample interval must be positive")
+	}
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		var (
+			latest    T
+			hasLatest bool
+		)
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if hasLatest {
+						out <- latest
+					}
+					return
+				}
+				latest, hasLatest = v, true
+			case <-ticker.C:
+				if hasLatest {
+					out <- latest
+					var zero T
+					latest, hasLatest = zero, false
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// BufferTime собирает значения в срезы по окнам длительностью d и
+// отправляет каждый непустой срез в конце окна. При закрытии in
+// накопленный срез отправляется сразу. d должен быть положительным.
+func BufferTime[T any](d time.Duration, in <-chan T) <-chan []T {
+	if d <= 0 {
+		panic("debounce: buffer interval must be positive")
+	}
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		var buf []T
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if len(buf) > 0 {
+						out <- buf
+					}
+					return
+				}
+				buf = append(buf, v)
+			case <-ticker.C:
+				if len(buf) > 0 {
+					out <- buf
+					buf = nil // срез передан получателю
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,75 @@
+package debounce
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSample(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := Sample(25*time.Millisecond, in)
+	go func() {
+		in <- 1
+		in <- 2
+		time.Sleep(60 * time.Millisecond) // два тика, второй без значений
+		in <- 3
+		close(in)
+	}()
+	if vals, _ := collect(out, time.Now()); !slices.Equal(vals, []int{2, 3}) {
+		t.Fatalf("ожидалось [2 3], получено %v", vals)
+	}
+}
+
+func TestSampleContinuous(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := Sample(20*time.Millisecond, in)
+	go burst(in, 20, 5*time.Millisecond)
+	vals, _ := collect(out, time.Now())
+	if len(vals) < 3 || vals[len(vals)-1] != 20 {
+		t.Fatalf("ожидались периодические значения, последнее 20, получено %v", vals)
+	}
+	if !slices.IsSorted(vals) {
+		t.Errorf("значения должны идти по порядку: %v", vals)
+	}
+}
+
+func TestBufferTime(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := BufferTime(30*time.Millisecond, in)
+	go func() {
+		in <- 1
+		in <- 2
+		in <- 3
+		time.Sleep(75 * time.Millisecond) // пустое окно не отправляется
+		in <- 4
+		close(in)
+	}()
+	var got [][]int
+	for b := range out {
+		got = append(got, b)
+	}
+	if len(got) != 2 || !slices.Equal(got[0], []int{1, 2, 3}) || !slices.Equal(got[1], []int{4}) {
+		t.Fatalf("ожидалось [[1 2 3] [4]], получено %v", got)
+	}
+}
+
+func TestSampleBufferTimePanicOnZero(t *testing.T) {
+	t.Parallel()
+	for name, f := range map[string]func(){
+		"Sample":     func() { Sample(0, make(chan int)) },
+		"BufferTime": func() { BufferTime(0, make(chan int)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: ожидалась паника при нулевом интервале", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,103 @@
+package debounce
+
+import "time"
+
+// Throttle отдаёт не больше одного значения за интервал d: первое
+// значение отправляется сразу, а следующие до конца интервала
+// отбрасываются. С WithTrailing(true) последнее отброшенное значение
+// отправляется в конце интервала и открывает новый интервал; с
+// WithLeading(false) отправляются только такие значения, поэтому
+// WithLeading(false) без WithTrailing(true) не отправляет ничего.
+// WithMaxWait не действует. При закрытии in ожидающее значение
+// отправляется сразу.
+func Throttle[T any](d time.Duration, in <-chan T, opts ...Option) <-chan T {
+	o := applyOptions(options{leading: true}, opts)
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		var (
+			pending    T
+			hasPending bool
+		)
+		window := time.NewTimer(d)
+		window.Stop()
+		defer window.Stop()
+		var windowC <-chan time.Time // nil, пока интервал не открыт
+
+		open := func() {
+			window.Reset(d)
+			windowC = window.C
+		}
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if o.trailing && hasPending {
+						out <- pending
+					}
+					return
+				}
+				if windowC == nil {
+					open()
+					if o.leading {
+						out <- v
+						continue
+					}
+				}
+				if o.trailing {
+					pending, hasPending = v, true
+				}
+			case <-windowC:
+				windowC = nil
+				if hasPending {
+					out <- pending
+					var zero T
+					pending, hasPending = zero, false
+					open()
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Audit после прихода значения ждёт d и отправляет последнее значение,
+// полученное за это время; следующий интервал начинается со следующего
+// значения. В отличие от Debounce, новые значения не продлевают
+// ожидание. При закрытии in ожидающее значение отправляется сразу.
+func Audit[T any](d time.Duration, in <-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		var (
+			latest    T
+			hasLatest bool
+		)
+		timer := time.NewTimer(d)
+		timer.Stop()
+		defer timer.Stop()
+		var timerC <-chan time.Time // nil, пока интервал не открыт
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if hasLatest {
+						out <- latest
+					}
+					return
+				}
+				latest, hasLatest = v, true
+				if timerC == nil {
+					timer.Reset(d)
+					timerC = timer.C
+				}
+			case <-timerC:
+				timerC = nil
+				out <- latest
+				var zero T
+				latest, hasLatest = zero, false
+			}
+		}
+	}()
+	return out
+}
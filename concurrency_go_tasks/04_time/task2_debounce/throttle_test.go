@@ -0,0 +1,105 @@
+package debounce
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// burst отправляет значения 1..n с интервалом gap и закрывает канал.
+func burst(in chan<- int, n int, gap time.Duration) {
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = i + 1
+	}
+	sendEvery(in, gap, vals...)
+}
+
+func TestThrottleLeading(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := Throttle(35*time.Millisecond, in)
+	go burst(in, 10, 10*time.Millisecond) // 1..10 за ~90мс
+	vals, at := collect(out, time.Now())
+	if len(vals) < 2 || len(vals) > 4 || vals[0] != 1 {
+		t.Fatalf("ожидалось 2-4 значения, начиная с 1, получено %v", vals)
+	}
+	if at[0] > 10*time.Millisecond {
+		t.Errorf("первое значение должно отправляться сразу, пришло через %s", at[0])
+	}
+	for i := 1; i < len(at); i++ {
+		if gap := at[i] - at[i-1]; gap < 30*time.Millisecond {
+			t.Errorf("значения %v и %v пришли с интервалом %s, меньше 35мс", vals[i-1], vals[i], gap)
+		}
+	}
+}
+
+func TestThrottleTrailing(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := Throttle(30*time.Millisecond, in, WithTrailing(true))
+	go func() {
+		in <- 1
+		in <- 2
+		in <- 3
+		time.Sleep(80 * time.Millisecond)
+		close(in)
+	}()
+	vals, at := collect(out, time.Now())
+	if !slices.Equal(vals, []int{1, 3}) {
+		t.Fatalf("ожидалось [1 3], получено %v", vals)
+	}
+	if at[1] < 25*time.Millisecond {
+		t.Errorf("завершающее значение должно прийти в конце интервала, пришло через %s", at[1])
+	}
+}
+
+func TestThrottleTrailingOnly(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := Throttle(20*time.Millisecond, in, WithLeading(false), WithTrailing(true))
+	go func() {
+		in <- 1
+		in <- 2
+		time.Sleep(50 * time.Millisecond)
+		close(in)
+	}()
+	if vals, _ := collect(out, time.Now()); !slices.Equal(vals, []int{2}) {
+		t.Fatalf("ожидалось [2], получено %v", vals)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := Audit(30*time.Millisecond, in)
+	go func() {
+		in <- 1
+		time.Sleep(10 * time.Millisecond)
+		in <- 2
+		time.Sleep(40 * time.Millisecond) // интервал закончился на 30мс
+		in <- 3
+		time.Sleep(40 * time.Millisecond)
+		close(in)
+	}()
+	vals, at := collect(out, time.Now())
+	if !slices.Equal(vals, []int{2, 3}) {
+		t.Fatalf("ожидалось [2 3], получено %v", vals)
+	}
+	if at[0] < 25*time.Millisecond || at[0] > 45*time.Millisecond {
+		t.Errorf("значение должно прийти через 30мс после первого в интервале, пришло через %s", at[0])
+	}
+}
+
+func TestAuditFlushOnClose(t *testing.T) {
+	t.Parallel()
+	in := make(chan int)
+	out := Audit(time.Hour, in)
+	go func() {
+		in <- 7
+		close(in)
+	}()
+	if vals, _ := collect(out, time.Now()); !slices.Equal(vals, []int{7}) {
+		t.Fatalf("ожидалось [7], получено %v", vals)
+	}
+}
@@ -1,26 +1,239 @@
 package cache
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
+
+// Loader загружает актуальное значение по ключу. Используется для фонового
+// обновления устаревших записей.
+type Loader func(key string) (interface{}, error)
+
+// Option настраивает кэш при создании.
+type Option func(*Cache)
+
+// WithSoftTTL задаёт мягкий срок жизни записи. После него Get по-прежнему
+// возвращает значение, но запускает его фоновое обновление через Loader.
+func WithSoftTTL(d time.Duration) Option {
+	return func(c *Cache) { c.softTTL = d }
+}
+
+// WithHardTTL задаёт жёсткий срок жизни записи, после которого она удаляется:
+// при обращении к ней или при очередном Set, даже если её больше не читают.
+func WithHardTTL(d time.Duration) Option {
+	return func(c *Cache) { c.hardTTL = d }
+}
+
+// WithLoader регистрирует функцию фонового обновления устаревших записей.
+func WithLoader(l Loader) Option {
+	return func(c *Cache) { c.loader = l }
+}
+
+// entry хранит значение и моменты его устаревания. Нулевое время означает
+// отсутствие соответствующего срока. gen меняется при каждой записи и
+// защищает от перезаписи свежего значения результатом старого обновления.
+type entry struct {
+	value      interface{}
+	softExpire time.Time
+	hardExpire time.Time
+	gen        uint64
+}
+
+func (e entry) stale(now time.Time) bool {
+	return !e.softExpire.IsZero() && !now.Before(e.softExpire)
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.hardExpire.IsZero() && !now.Before(e.hardExpire)
+}
 
 // Cache представляет потокобезопасный кэш.
 type Cache struct {
 	mu   sync.RWMutex
-	data map[string]interface{}
+	data map[string]entry
+	gen  uint64
+
+	softTTL time.Duration
+	hardTTL time.Duration
+	loader  Loader
+
+	refreshing map[string]struct{}
+	refreshWG  sync.WaitGroup
+
+	stats counters
+	hooks hooks
+
+	bus  Transport
+	node string
+
+	nextSweep time.Time // раньше этого момента Set не ищет просроченные записи
+
+	now func() time.Time
 }
 
 // New создаёт новый кэш.
-func New() *Cache {
-	// TODO: инициализировать структуру кэша
-	return &Cache{}
+func New(opts ...Option) *Cache {
+	c := &Cache{
+		data:       make(map[string]entry),
+		refreshing: make(map[string]struct{}),
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Set сохраняет значение по ключу.
 func (c *Cache) Set(key string, value interface{}) {
-	// TODO: реализовать запись с использованием RWMutex
+	now := c.now()
+	c.mu.Lock()
+	c.store(key, value, now)
+	expired := c.sweep(now)
+	c.mu.Unlock()
+	c.hooks.set(key, value)
+	for _, e := range expired {
+		c.stats.expirations.Add(1)
+		c.hooks.expire(e.key, e.value)
+	}
+	c.publish(OpSet, key)
+}
+
+// sweepInterval — период поиска просроченных записей, если жёсткий срок
+// не задан, но записи со сроком появились через Restore.
+const sweepInterval = time.Minute
+
+type expiredEntry struct {
+	key   string
+	value interface{}
+}
+
+// sweep удаляет все просроченные записи, но не чаще раза за жёсткий
+// срок, поэтому в среднем Set остаётся дешёвым. Без него ключи, которые
+// больше никто не читает, не удалялись бы никогда. Вызывается под c.mu.
+func (c *Cache) sweep(now time.Time) []expiredEntry {
+	if now.Before(c.nextSweep) {
+		return nil
+	}
+	interval := c.hardTTL
+	if interval <= 0 {
+		interval = sweepInterval
+	}
+	c.nextSweep = now.Add(interval)
+	var expired []expiredEntry
+	for key, e := range c.data {
+		if e.expired(now) {
+			delete(c.data, key)
+			expired = append(expired, expiredEntry{key, e.value})
+		}
+	}
+	return expired
+}
+
+// Delete удаляет значение по ключу. Удаление существующей записи
+// учитывается как вытеснение.
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	e, ok := c.data[key]
+	delete(c.data, key)
+	c.mu.Unlock()
+	if ok {
+		c.stats.evictions.Add(1)
+		c.hooks.evict(key, e.value)
+	}
+	c.publish(OpDelete, key)
+}
+
+// store записывает значение со сроками, отсчитанными от now.
+// Вызывается под c.mu.
+func (c *Cache) store(key string, value interface{}, now time.Time) {
+	c.gen++
+	e := entry{value: value, gen: c.gen}
+	if c.softTTL > 0 {
+		e.softExpire = now.Add(c.softTTL)
+	}
+	if c.hardTTL > 0 {
+		e.hardExpire = now.Add(c.hardTTL)
+	}
+	c.data[key] = e
 }
 
 // Get возвращает значение по ключу и признак его наличия.
+// Устаревшее по мягкому сроку значение возвращается как есть,
+// а его обновление запускается в фоне.
 func (c *Cache) Get(key string) (interface{}, bool) {
-	// TODO: реализовать чтение с использованием RWMutex
-	return nil, false
+	now := c.now()
+	c.mu.RLock()
+	e, ok := c.data[key]
+	c.mu.RUnlock()
+	if !ok {
+		c.stats.misses.Add(1)
+		return nil, false
+	}
+	if e.expired(now) {
+		c.stats.misses.Add(1)
+		c.removeExpired(key, now)
+		return nil, false
+	}
+	c.stats.hits.Add(1)
+	if e.stale(now) {
+		c.stats.staleHits.Add(1)
+		c.refresh(key, e.gen)
+	}
+	return e.value, true
+}
+
+// removeExpired удаляет запись, если она всё ещё просрочена: между чтением
+// и захватом блокировки её могли перезаписать.
+func (c *Cache) removeExpired(key string, now time.Time) {
+	c.mu.Lock()
+	e, ok := c.data[key]
+	ok = ok && e.expired(now)
+	if ok {
+		delete(c.data, key)
+	}
+	c.mu.Unlock()
+	if ok {
+		c.stats.expirations.Add(1)
+		c.hooks.expire(key, e.value)
+	}
+}
+
+// refresh запускает фоновое обновление ключа, если оно ещё не идёт.
+func (c *Cache) refresh(key string, gen uint64) {
+	if c.loader == nil {
+		return
+	}
+	c.mu.Lock()
+	if _, busy := c.refreshing[key]; busy {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = struct{}{}
+	c.refreshWG.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.refreshWG.Done()
+		value, err := c.loader(key)
+		now := c.now()
+
+		c.mu.Lock()
+		delete(c.refreshing, key)
+		if err != nil {
+			// Оставляем устаревшее значение, следующий Get повторит попытку.
+			c.mu.Unlock()
+			c.stats.refreshErrors.Add(1)
+			return
+		}
+		e, ok := c.data[key]
+		ok = ok && e.gen == gen
+		if ok {
+			c.store(key, value, now)
+		}
+		c.mu.Unlock()
+		if ok {
+			c.hooks.set(key, value)
+		}
+	}()
 }
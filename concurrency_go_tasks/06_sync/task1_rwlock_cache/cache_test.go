@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"errors"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -253,3 +254,170 @@ func BenchmarkCacheConcurrent(b *testing.B) {
 		}
 	})
 }
+
+// fakeClock позволяет управлять временем кэша в тестах.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	f.mu.Unlock()
+}
+
+func TestCacheHardTTL(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	c := New(WithHardTTL(time.Minute))
+	c.now = clock.Now
+
+	c.Set("key", "value")
+	clock.Advance(59 * time.Second)
+	if _, ok := c.Get("key"); !ok {
+		t.Fatal("запись не должна истечь до жёсткого срока")
+	}
+	clock.Advance(time.Second)
+	if _, ok := c.Get("key"); ok {
+		t.Fatal("запись должна исчезнуть после жёсткого срока")
+	}
+	c.mu.RLock()
+	_, stored := c.data["key"]
+	c.mu.RUnlock()
+	if stored {
+		t.Error("просроченная запись должна удаляться из хранилища")
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	var loads int32
+	release := make(chan struct{})
+	c := New(
+		WithSoftTTL(time.Second),
+		WithHardTTL(time.Minute),
+		WithLoader(func(key string) (interface{}, error) {
+			atomic.AddInt32(&loads, 1)
+			<-release
+			return "fresh", nil
+		}),
+	)
+	c.now = clock.Now
+
+	c.Set("key", "stale")
+	clock.Advance(2 * time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, ok := c.Get("key"); !ok || v != "stale" {
+				t.Errorf("ожидалось устаревшее значение 'stale', получено %v", v)
+			}
+		}()
+	}
+	wg.Wait()
+	close(release)
+	c.refreshWG.Wait()
+
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Errorf("ожидался ровно один фоновый вызов загрузчика, получено %d", n)
+	}
+	if v, _ := c.Get("key"); v != "fresh" {
+		t.Errorf("после обновления ожидалось 'fresh', получено %v", v)
+	}
+}
+
+func TestCacheRefreshErrorKeepsStale(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	var loads int32
+	c := New(
+		WithSoftTTL(time.Second),
+		WithLoader(func(key string) (interface{}, error) {
+			atomic.AddInt32(&loads, 1)
+			return nil, errors.New("backend down")
+		}),
+	)
+	c.now = clock.Now
+
+	c.Set("key", "stale")
+	clock.Advance(2 * time.Second)
+	for i := 0; i < 3; i++ {
+		if v, ok := c.Get("key"); !ok || v != "stale" {
+			t.Fatalf("при ошибке загрузчика ожидалось 'stale', получено %v", v)
+		}
+		c.refreshWG.Wait()
+	}
+	if n := atomic.LoadInt32(&loads); n != 3 {
+		t.Errorf("каждый Get после ошибки должен повторять обновление: ожидалось 3 вызова, получено %d", n)
+	}
+}
+
+func TestCacheRefreshDoesNotOverwriteNewerSet(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c := New(
+		WithSoftTTL(time.Second),
+		WithLoader(func(key string) (interface{}, error) {
+			close(started)
+			<-release
+			return "loaded", nil
+		}),
+	)
+	c.now = clock.Now
+
+	c.Set("key", "old")
+	clock.Advance(2 * time.Second)
+	c.Get("key")
+	<-started
+	c.Set("key", "newer")
+	close(release)
+	c.refreshWG.Wait()
+
+	if v, _ := c.Get("key"); v != "newer" {
+		t.Errorf("фоновое обновление не должно затирать более новое значение, получено %v", v)
+	}
+}
+
+func TestCacheSetSweepsUnreadExpired(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	var expired []string
+	c := New(WithHardTTL(time.Minute), OnExpire(func(key string, _ interface{}) {
+		expired = append(expired, key)
+	}))
+	c.now = clock.Now
+
+	c.Set("unread", 1)
+	clock.Advance(time.Minute)
+	c.Set("fresh", 2)
+
+	c.mu.RLock()
+	_, stored := c.data["unread"]
+	c.mu.RUnlock()
+	if stored {
+		t.Error("просроченная запись, которую никто не читает, должна удаляться при Set")
+	}
+	if len(expired) != 1 || expired[0] != "unread" {
+		t.Errorf("ожидался OnExpire для unread, получено %v", expired)
+	}
+	if s := c.Stats(); s.Entries != 1 || s.Expirations != 1 {
+		t.Errorf("ожидались 1 запись и 1 истечение, получено %+v", s)
+	}
+}
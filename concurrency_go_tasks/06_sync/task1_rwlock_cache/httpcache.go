@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpKeyPrefix отделяет записи HTTP-кэша от остальных данных в том же Cache.
+const httpKeyPrefix = "http:"
+
+// cachedResponse — сохранённый ответ вместе с моментом его устаревания.
+type cachedResponse struct {
+	Status  int
+	Header  http.Header
+	Body    []byte
+	Stored  time.Time
+	Expires time.Time
+}
+
+// Ответы хранятся в Cache как значения interface{}, поэтому для Snapshot
+// тип нужно зарегистрировать в gob; вызывающий сделать этого не может.
+func init() {
+	gob.Register(&cachedResponse{})
+}
+
+func (r *cachedResponse) etag() string {
+	return r.Header.Get("ETag")
+}
+
+// Middleware кэширует ответы на GET-запросы в c. Ключом служат метод,
+// URL и значения заголовков, перечисленных в Vary ответа. Ответ
+// сохраняется, если он успешен и содержит Cache-Control: max-age (или
+// s-maxage) без no-store и private; ответ с no-cache сохраняется уже
+// устаревшим и перед каждым использованием перепроверяется. Кэш
+// разделяемый, поэтому ответы с Set-Cookie и ответы на запросы с
+// Authorization (кроме разрешённых public, s-maxage или must-revalidate,
+// RFC 9111 §3.5) не сохраняются. Ответы, которые нельзя сохранить,
+// передаются клиенту сразу, без буферизации, так что потоковые
+// обработчики и http.Flusher продолжают работать. Запрос с
+// If-None-Match, совпадающим с ETag свежего ответа, получает 304.
+// Устаревший ответ с ETag перепроверяется у обработчика условным
+// запросом, и при 304 его срок продлевается без передачи тела. Сколько
+// хранятся устаревшие ответы, определяет жёсткий срок кэша.
+func Middleware(c *Cache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || hasDirective(r.Header, "no-store") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			base := httpKeyPrefix + r.Method + " " + r.URL.String()
+			var vary []string
+			if v, ok := c.Get(base); ok {
+				vary, _ = v.([]string)
+			}
+			key := variantKey(base, vary, r.Header)
+
+			var cached *cachedResponse
+			if v, ok := c.Get(key); ok {
+				cached, _ = v.(*cachedResponse)
+			}
+			now := c.now()
+			if cached != nil && now.Before(cached.Expires) && !hasDirective(r.Header, "no-cache") {
+				serveCached(w, r, cached, now, "HIT")
+				return
+			}
+
+			// Условные заголовки клиента обрабатываем сами, а у обработчика
+			// запрашиваем либо полный ответ, либо проверку сохранённого ETag.
+			upstream := r.Clone(r.Context())
+			upstream.Header.Del("If-None-Match")
+			upstream.Header.Del("If-Modified-Since")
+			if cached != nil && cached.etag() != "" {
+				upstream.Header.Set("If-None-Match", cached.etag())
+			}
+
+			rec := &responseRecorder{
+				w:      w,
+				header: make(http.Header),
+				status: http.StatusOK,
+				buffer: func(status int, h http.Header) bool {
+					if status == http.StatusNotModified {
+						return cached != nil
+					}
+					return status == http.StatusOK && storable(r, h)
+				},
+			}
+			next.ServeHTTP(rec, upstream)
+			rec.WriteHeader(http.StatusOK) // обработчик мог ничего не записать
+			if rec.passthrough {
+				return
+			}
+			now = c.now()
+
+			if cached != nil && rec.status == http.StatusNotModified {
+				revalidated := *cached
+				revalidated.Header = cached.Header.Clone()
+				for _, h := range []string{"Cache-Control", "Expires", "ETag", "Date"} {
+					if v := rec.header.Values(h); len(v) > 0 {
+						revalidated.Header[h] = v
+					}
+				}
+				revalidated.Stored = now
+				revalidated.Expires = now
+				if ttl, ok := freshness(revalidated.Header); ok && shareable(r, rec.header) {
+					revalidated.Expires = now.Add(ttl)
+					c.Set(key, &revalidated)
+				}
+				serveCached(w, r, &revalidated, now, "REVALIDATED")
+				return
+			}
+
+			resp := &cachedResponse{
+				Status: rec.status,
+				Header: rec.header,
+				Body:   rec.body.Bytes(),
+				Stored: now,
+			}
+			ttl, _ := freshness(resp.Header)
+			names, _ := varyNames(resp.Header)
+			resp.Expires = now.Add(ttl)
+			c.Set(base, names)
+			c.Set(variantKey(base, names, r.Header), resp)
+			serveCached(w, r, resp, now, "MISS")
+		})
+	}
+}
+
+// serveCached отдаёт сохранённый ответ клиенту, отвечая 304, если его
+// If-None-Match совпадает с ETag ответа.
+func serveCached(w http.ResponseWriter, r *http.Request, resp *cachedResponse, now time.Time, status string) {
+	h := w.Header()
+	for k, v := range resp.Header {
+		h[k] = append([]string(nil), v...)
+	}
+	h.Set("X-Cache", status)
+	if status == "HIT" {
+		h.Set("Age", strconv.Itoa(int(now.Sub(resp.Stored)/time.Second)))
+	}
+	if resp.Status == http.StatusOK && etagMatches(r.Header.Get("If-None-Match"), resp.etag()) {
+		h.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// variantKey дополняет базовый ключ значениями заголовков запроса из Vary.
+func variantKey(base string, vary []string, h http.Header) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, name := range vary {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strings.Join(h.Values(name), ","))
+	}
+	return b.String()
+}
+
+// varyNames возвращает канонические имена заголовков из Vary. Ответ с
+// Vary: * кэшировать нельзя, тогда второй результат равен false.
+func varyNames(h http.Header) ([]string, bool) {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			switch name {
+			case "":
+				continue
+			case "*":
+				return nil, false
+			}
+			names = append(names, http.CanonicalHeaderKey(name))
+		}
+	}
+	return names, true
+}
+
+// freshness возвращает срок свежести ответа из Cache-Control.
+// s-maxage имеет приоритет над max-age, как для разделяемого кэша.
+func freshness(h http.Header) (time.Duration, bool) {
+	if hasDirective(h, "no-store") || hasDirective(h, "private") {
+		return 0, false
+	}
+	if hasDirective(h, "no-cache") {
+		// Ответ можно хранить, но не отдавать без перепроверки
+		// (RFC 9111 §5.2.2.4), поэтому он устаревает сразу.
+		return 0, true
+	}
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if v, ok := directive(h, name); ok {
+			secs, err := strconv.Atoi(v)
+			if err != nil || secs <= 0 {
+				return 0, false
+			}
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// storable сообщает, можно ли сохранить успешный ответ с заголовками h
+// на запрос r.
+func storable(r *http.Request, h http.Header) bool {
+	if _, ok := freshness(h); !ok || !shareable(r, h) {
+		return false
+	}
+	_, ok := varyNames(h)
+	return ok
+}
+
+// shareable сообщает, можно ли отдавать ответ на запрос r другим
+// клиентам. Ответ с Set-Cookie относится к одному пользователю, а ответ
+// на запрос с Authorization — только если сервер явно разрешил его
+// разделять.
+func shareable(r *http.Request, h http.Header) bool {
+	if len(h.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	if r.Header.Get("Authorization") == "" {
+		return true
+	}
+	return hasDirective(h, "public") || hasDirective(h, "s-maxage") || hasDirective(h, "must-revalidate")
+}
+
+// directive ищет директиву Cache-Control и возвращает её значение.
+func directive(h http.Header, name string) (string, bool) {
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			k, val, _ := strings.Cut(strings.TrimSpace(d), "=")
+			if strings.EqualFold(k, name) {
+				return strings.Trim(val, `"`), true
+			}
+		}
+	}
+	return "", false
+}
+
+func hasDirective(h http.Header, name string) bool {
+	_, ok := directive(h, name)
+	return ok
+}
+
+// etagMatches сравнивает If-None-Match с ETag по слабому правилу RFC 9110:
+// префикс W/ не учитывается.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// responseRecorder буферизует ответ обработчика, чтобы его можно было
+// сохранить и отдать клиенту. Если по статусу и заголовкам buffer решает,
+// что ответ не сохраняется, он сразу передаётся в w.
+type responseRecorder struct {
+	w           http.ResponseWriter
+	buffer      func(status int, h http.Header) bool
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+	passthrough bool
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.status = status
+	if r.buffer(status, r.header) {
+		return
+	}
+	r.passthrough = true
+	h := r.w.Header()
+	for k, v := range r.header {
+		h[k] = v
+	}
+	h.Set("X-Cache", "MISS")
+	r.w.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	if r.passthrough {
+		return r.w.Write(p)
+	}
+	return r.body.Write(p)
+}
+
+// Flush передаёт данные клиенту, если ответ не буферизуется.
+func (r *responseRecorder) Flush() {
+	r.WriteHeader(http.StatusOK)
+	if f, ok := r.w.(http.Flusher); ok && r.passthrough {
+		f.Flush()
+	}
+}
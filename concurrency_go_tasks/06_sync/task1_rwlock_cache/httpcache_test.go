@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingHandler отвечает телом с номером вызова и заданными заголовками.
+func countingHandler(calls *int32, header http.Header) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		fmt.Fprintf(w, "response %d", n)
+	})
+}
+
+func doGet(h http.Handler, url string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareCachesByMaxAge(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	c := New()
+	c.now = clock.Now
+	var calls int32
+	h := Middleware(c)(countingHandler(&calls, http.Header{"Cache-Control": {"max-age=60"}}))
+
+	first := doGet(h, "/a", nil)
+	second := doGet(h, "/a", nil)
+	if first.Body.String() != "response 1" || second.Body.String() != "response 1" {
+		t.Fatalf("повторный запрос должен обслуживаться из кэша: %q, %q", first.Body, second.Body)
+	}
+	if got := second.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("ожидался X-Cache: HIT, получено %q", got)
+	}
+	if body := doGet(h, "/b", nil).Body.String(); body != "response 2" {
+		t.Errorf("другой URL должен кэшироваться отдельно, получено %q", body)
+	}
+
+	clock.Advance(61 * time.Second)
+	if body := doGet(h, "/a", nil).Body.String(); body != "response 3" {
+		t.Errorf("после max-age ответ должен запрашиваться заново, получено %q", body)
+	}
+}
+
+func TestMiddlewareNoStore(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	h := Middleware(New())(countingHandler(&calls, http.Header{"Cache-Control": {"no-store, max-age=60"}}))
+	doGet(h, "/", nil)
+	doGet(h, "/", nil)
+	if calls != 2 {
+		t.Errorf("ответ с no-store не должен кэшироваться: ожидалось 2 вызова, получено %d", calls)
+	}
+
+	calls = 0
+	h = Middleware(New())(countingHandler(&calls, http.Header{"Cache-Control": {"max-age=60"}}))
+	noStore := http.Header{"Cache-Control": {"no-store"}}
+	doGet(h, "/", noStore)
+	doGet(h, "/", noStore)
+	doGet(h, "/", nil)
+	if calls != 3 {
+		t.Errorf("запрос с no-store не должен читать и сохранять кэш: ожидалось 3 вызова, получено %d", calls)
+	}
+}
+
+func TestMiddlewarePrivateResponses(t *testing.T) {
+	t.Parallel()
+	auth := http.Header{"Authorization": {"Bearer alice"}}
+	for _, tc := range []struct {
+		name   string
+		req    http.Header
+		resp   http.Header
+		stored bool
+	}{
+		{"authorization", auth, http.Header{"Cache-Control": {"max-age=60"}}, false},
+		{"authorization public", auth, http.Header{"Cache-Control": {"public, max-age=60"}}, true},
+		{"authorization s-maxage", auth, http.Header{"Cache-Control": {"s-maxage=60"}}, true},
+		{"authorization must-revalidate", auth, http.Header{"Cache-Control": {"max-age=60, must-revalidate"}}, true},
+		{"set-cookie", nil, http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"session=alice"}}, false},
+	} {
+		var calls int32
+		h := Middleware(New())(countingHandler(&calls, tc.resp))
+		doGet(h, "/", tc.req)
+		doGet(h, "/", nil)
+		if stored := calls == 1; stored != tc.stored {
+			t.Errorf("%s: ожидалось сохранение %v, вызовов обработчика %d", tc.name, tc.stored, calls)
+		}
+	}
+}
+
+func TestMiddlewareSkipsNonGet(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	h := Middleware(New())(countingHandler(&calls, http.Header{"Cache-Control": {"max-age=60"}}))
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("POST не должен кэшироваться: ожидалось 2 вызова, получено %d", calls)
+	}
+}
+
+func TestMiddlewareVary(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	h := Middleware(New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "accept-language")
+		fmt.Fprint(w, r.Header.Get("Accept-Language"))
+	}))
+
+	en := http.Header{"Accept-Language": {"en"}}
+	ru := http.Header{"Accept-Language": {"ru"}}
+	if body := doGet(h, "/", en).Body.String(); body != "en" {
+		t.Fatalf("ожидалось 'en', получено %q", body)
+	}
+	if body := doGet(h, "/", ru).Body.String(); body != "ru" {
+		t.Fatalf("вариант для другого Accept-Language не должен браться из кэша, получено %q", body)
+	}
+	doGet(h, "/", en)
+	doGet(h, "/", ru)
+	if calls != 2 {
+		t.Errorf("ожидалось по одному вызову на вариант, получено %d", calls)
+	}
+}
+
+func TestMiddlewareIfNoneMatch(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	h := Middleware(New())(countingHandler(&calls, http.Header{
+		"Cache-Control": {"max-age=60"},
+		"Etag":          {`"v1"`},
+	}))
+
+	doGet(h, "/", nil)
+	rec := doGet(h, "/", http.Header{"If-None-Match": {`"v0", W/"v1"`}})
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("ожидался 304 при совпадении ETag, получено %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("ответ 304 не должен содержать тело, получено %q", rec.Body)
+	}
+	if rec := doGet(h, "/", http.Header{"If-None-Match": {`"other"`}}); rec.Code != http.StatusOK {
+		t.Errorf("при несовпадении ETag ожидался 200, получено %d", rec.Code)
+	}
+	if calls != 1 {
+		t.Errorf("условные запросы к свежему ответу не должны доходить до обработчика, получено %d вызовов", calls)
+	}
+}
+
+func TestMiddlewareRevalidatesStaleWithETag(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	c := New()
+	c.now = clock.Now
+	var full, conditional int32
+	h := Middleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=10")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		fmt.Fprint(w, "body")
+	}))
+
+	doGet(h, "/", nil)
+	clock.Advance(11 * time.Second)
+	rec := doGet(h, "/", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "body" {
+		t.Fatalf("после перепроверки ожидался сохранённый ответ, получено %d %q", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("X-Cache"); got != "REVALIDATED" {
+		t.Errorf("ожидался X-Cache: REVALIDATED, получено %q", got)
+	}
+	if full != 1 || conditional != 1 {
+		t.Errorf("ожидался 1 полный и 1 условный запрос, получено %d и %d", full, conditional)
+	}
+
+	if got := doGet(h, "/", nil).Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("перепроверка должна продлевать свежесть ответа, получено X-Cache %q", got)
+	}
+}
+
+func TestMiddlewareSnapshotRoundTrip(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	src := New()
+	h := Middleware(src)(countingHandler(&calls, http.Header{
+		"Cache-Control": {"max-age=60"},
+		"Vary":          {"Accept"},
+	}))
+	doGet(h, "/a", http.Header{"Accept": {"text/plain"}})
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot кэша с ответами Middleware: %v", err)
+	}
+	dst := New()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	rec := doGet(Middleware(dst)(countingHandler(&calls, nil)), "/a", http.Header{"Accept": {"text/plain"}})
+	if rec.Body.String() != "response 1" || rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("восстановленный ответ должен отдаваться из кэша, получено %q (X-Cache %q)",
+			rec.Body, rec.Header().Get("X-Cache"))
+	}
+}
+
+func TestMiddlewareNoCacheRevalidates(t *testing.T) {
+	t.Parallel()
+	var full, conditional int32
+	h := Middleware(New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache, max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		fmt.Fprint(w, "body")
+	}))
+
+	doGet(h, "/", nil)
+	rec := doGet(h, "/", nil)
+	if got := rec.Header().Get("X-Cache"); got != "REVALIDATED" || rec.Body.String() != "body" {
+		t.Errorf("ответ с no-cache должен перепроверяться, получено X-Cache %q, тело %q", got, rec.Body)
+	}
+	if full != 1 || conditional != 1 {
+		t.Errorf("ожидался 1 полный и 1 условный запрос, получено %d и %d", full, conditional)
+	}
+}
+
+// flushRecorder отмечает вызовы Flush и запоминает, что было записано к
+// этому моменту.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed []string
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushed = append(f.flushed, f.Body.String())
+}
+
+func TestMiddlewareStreamsUncacheable(t *testing.T) {
+	t.Parallel()
+	h := Middleware(New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event 1\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "event 2\n")
+	}))
+	rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if len(rec.flushed) != 1 || rec.flushed[0] != "event 1\n" {
+		t.Errorf("несохраняемый ответ должен передаваться клиенту сразу, при Flush было записано %q", rec.flushed)
+	}
+	if rec.Body.String() != "event 1\nevent 2\n" || rec.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("неожиданный ответ: %q, X-Cache %q", rec.Body, rec.Header().Get("X-Cache"))
+	}
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Op — операция, вызвавшая инвалидацию.
+type Op uint8
+
+const (
+	OpSet    Op = iota + 1 // значение перезаписано через Set
+	OpDelete               // значение удалено через Delete
+)
+
+// Message сообщает соседним экземплярам, что ключ изменился и их копию
+// нужно удалить. Origin идентифицирует экземпляр-источник, чтобы он не
+// применял собственные сообщения.
+type Message struct {
+	Origin string `json:"origin"`
+	Op     Op     `json:"op"`
+	Key    string `json:"key"`
+}
+
+// Transport доставляет сообщения об инвалидации между экземплярами кэша.
+type Transport interface {
+	// Publish отправляет сообщение всем остальным участникам.
+	Publish(m Message) error
+	// Subscribe регистрирует обработчик входящих сообщений.
+	Subscribe(h func(Message))
+	// Close отключает транспорт.
+	Close() error
+}
+
+// WithInvalidation подключает кэш к шине инвалидации: Set и Delete
+// публикуют сообщение, а полученные от соседей сообщения удаляют ключ
+// локально. Подключением и закрытием транспорта управляет вызывающий.
+func WithInvalidation(t Transport) Option {
+	return func(c *Cache) {
+		c.bus = t
+		c.node = newNodeID()
+		t.Subscribe(c.applyInvalidation)
+	}
+}
+
+// publish рассылает сообщение об изменении ключа, если шина подключена.
+func (c *Cache) publish(op Op, key string) {
+	if c.bus == nil {
+		return
+	}
+	if err := c.bus.Publish(Message{Origin: c.node, Op: op, Key: key}); err != nil {
+		c.stats.publishErrors.Add(1)
+	}
+}
+
+// applyInvalidation удаляет ключ по сообщению соседа, не публикуя его дальше.
+func (c *Cache) applyInvalidation(m Message) {
+	if m.Origin == c.node {
+		return
+	}
+	c.mu.Lock()
+	_, ok := c.data[m.Key]
+	delete(c.data, m.Key)
+	c.mu.Unlock()
+	if ok {
+		c.stats.invalidations.Add(1)
+	}
+}
+
+func newNodeID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
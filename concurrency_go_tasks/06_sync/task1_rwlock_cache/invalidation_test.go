@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInvalidationMemoryHub(t *testing.T) {
+	t.Parallel()
+	hub := NewMemoryHub()
+	a := New(WithInvalidation(hub.Transport()))
+	b := New(WithInvalidation(hub.Transport()))
+	c := New(WithInvalidation(hub.Transport()))
+
+	b.Set("key", "b")
+	c.Set("key", "c")
+	if _, ok := b.Get("key"); ok {
+		t.Fatal("запись в c должна инвалидировать копию в b")
+	}
+
+	a.Set("key", "a")
+	if v, ok := a.Get("key"); !ok || v != "a" {
+		t.Fatalf("собственное сообщение не должно удалять локальное значение, получено %v", v)
+	}
+	if _, ok := c.Get("key"); ok {
+		t.Fatal("запись в a должна инвалидировать копию в c")
+	}
+
+	b.Set("other", 1)
+	a.Delete("other")
+	if _, ok := b.Get("other"); ok {
+		t.Fatal("Delete в a должен инвалидировать копию в b")
+	}
+	if s := b.Stats(); s.Invalidations != 2 {
+		t.Errorf("ожидалось 2 инвалидации в b, получено %d", s.Invalidations)
+	}
+}
+
+func TestInvalidationDoesNotEcho(t *testing.T) {
+	t.Parallel()
+	hub := NewMemoryHub()
+	var published int
+	spy := &spyTransport{Transport: hub.Transport(), onPublish: func(Message) { published++ }}
+	a := New(WithInvalidation(spy))
+	b := New(WithInvalidation(hub.Transport()))
+
+	a.Set("key", 1)
+	b.Set("key", 2)
+	b.Delete("key")
+	if published != 1 {
+		t.Errorf("применённые сообщения не должны публиковаться повторно: ожидалась 1 публикация, получено %d", published)
+	}
+}
+
+func TestInvalidationPublishErrors(t *testing.T) {
+	t.Parallel()
+	tr := NewMemoryHub().Transport()
+	c := New(WithInvalidation(tr))
+	tr.Close()
+
+	c.Set("key", 1)
+	if v, ok := c.Get("key"); !ok || v != 1 {
+		t.Fatal("ошибка публикации не должна мешать локальной записи")
+	}
+	if s := c.Stats(); s.PublishErrors != 1 {
+		t.Errorf("ожидалась 1 ошибка публикации, получено %d", s.PublishErrors)
+	}
+	if err := tr.Publish(Message{}); !errors.Is(err, ErrTransportClosed) {
+		t.Errorf("ожидалась ErrTransportClosed, получено %v", err)
+	}
+}
+
+type spyTransport struct {
+	Transport
+	onPublish func(Message)
+}
+
+func (s *spyTransport) Publish(m Message) error {
+	s.onPublish(m)
+	return s.Transport.Publish(m)
+}
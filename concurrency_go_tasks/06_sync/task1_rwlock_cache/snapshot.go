@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// snapshotMagic открывает каждый снимок и отличает его от произвольных данных.
+var snapshotMagic = [4]byte{'C', 'S', 'N', 'P'}
+
+// snapshotVersion — текущая версия формата снимка.
+const snapshotVersion byte = 1
+
+var ErrBadSnapshot = errors.New("cache: not a snapshot")                   // данные не являются снимком кэша
+var ErrSnapshotVersion = errors.New("cache: unsupported snapshot version") // снимок записан в неизвестной версии формата
+
+// snapshotHeader предшествует записям и хранит их количество.
+type snapshotHeader struct {
+	Count int
+}
+
+// snapshotRecord — одна запись снимка. Сроки сохраняются абсолютными,
+// поэтому время простоя процесса засчитывается в срок жизни.
+type snapshotRecord struct {
+	Key        string
+	Value      interface{}
+	SoftExpire time.Time
+	HardExpire time.Time
+}
+
+// Snapshot записывает содержимое кэша в w вместе со сроками жизни записей.
+// Значения кодируются через encoding/gob, поэтому пользовательские типы
+// должны быть зарегистрированы через gob.Register.
+func (c *Cache) Snapshot(w io.Writer) error {
+	now := c.now()
+	c.mu.RLock()
+	records := make([]snapshotRecord, 0, len(c.data))
+	for key, e := range c.data {
+		if e.expired(now) {
+			continue
+		}
+		records = append(records, snapshotRecord{
+			Key:        key,
+			Value:      e.value,
+			SoftExpire: e.softExpire,
+			HardExpire: e.hardExpire,
+		})
+	}
+	c.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(snapshotMagic[:]); err != nil {
+		return err
+	}
+	if err := bw.WriteByte(snapshotVersion); err != nil {
+		return err
+	}
+	enc := gob.NewEncoder(bw)
+	if err := enc.Encode(snapshotHeader{Count: len(records)}); err != nil {
+		return err
+	}
+	for i := range records {
+		if err := enc.Encode(&records[i]); err != nil {
+			return fmt.Errorf("cache: encode %q: %w", records[i].Key, err)
+		}
+	}
+	return bw.Flush()
+}
+
+// Restore загружает записи из снимка, перезаписывая совпадающие ключи.
+// Записи, жёсткий срок которых истёк, пропускаются.
+func (c *Cache) Restore(r io.Reader) error {
+	br := bufio.NewReader(r)
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadSnapshot, err)
+	}
+	if magic != snapshotMagic {
+		return ErrBadSnapshot
+	}
+	version, err := br.ReadByte()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBadSnapshot, err)
+	}
+	if version != snapshotVersion {
+		return fmt.Errorf("%w: %d", ErrSnapshotVersion, version)
+	}
+
+	dec := gob.NewDecoder(br)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("%w: decode header: %v", ErrBadSnapshot, err)
+	}
+	if header.Count < 0 {
+		return fmt.Errorf("%w: negative record count %d", ErrBadSnapshot, header.Count)
+	}
+	// Count берётся из входных данных, поэтому память под записи не
+	// резервируется заранее: повреждённый заголовок не должен приводить
+	// к огромному выделению.
+	var records []snapshotRecord
+	for i := 0; i < header.Count; i++ {
+		var rec snapshotRecord
+		if err := dec.Decode(&rec); err != nil {
+			return fmt.Errorf("%w: decode record %d: %v", ErrBadSnapshot, i, err)
+		}
+		records = append(records, rec)
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rec := range records {
+		e := entry{value: rec.Value, softExpire: rec.SoftExpire, hardExpire: rec.HardExpire}
+		if e.expired(now) {
+			continue
+		}
+		c.gen++
+		e.gen = c.gen
+		c.data[rec.Key] = e
+	}
+	return nil
+}
+
+// SnapshotFile атомарно сохраняет снимок в файл path: данные пишутся во
+// временный файл рядом и переименовываются только после успешной записи.
+func (c *Cache) SnapshotFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err := c.Snapshot(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RestoreFile загружает снимок из файла path. Если файла нет, возвращается
+// ошибка, для которой errors.Is(err, os.ErrNotExist) истинно.
+func (c *Cache) RestoreFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Restore(f)
+}
+
+// SnapshotEvery сохраняет снимок в path каждые d и возвращает функцию
+// остановки. Ошибки сохранения передаются в onErr, если он задан.
+// Остановка дожидается завершения текущей записи и делает финальный снимок.
+func (c *Cache) SnapshotEvery(d time.Duration, path string, onErr func(error)) (stop func()) {
+	save := func() {
+		if err := c.SnapshotFile(path); err != nil && onErr != nil {
+			onErr(err)
+		}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				save()
+			case <-done:
+				save()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type snapshotPoint struct {
+	X, Y int
+}
+
+func init() {
+	gob.Register(snapshotPoint{})
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	t.Parallel()
+	src := New()
+	src.Set("str", "value")
+	src.Set("int", 42)
+	src.Set("point", snapshotPoint{X: 1, Y: 2})
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	dst := New()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	for key, want := range map[string]interface{}{
+		"str":   "value",
+		"int":   42,
+		"point": snapshotPoint{X: 1, Y: 2},
+	} {
+		if got, ok := dst.Get(key); !ok || got != want {
+			t.Errorf("%s: ожидалось %v, получено %v (ok=%v)", key, want, got, ok)
+		}
+	}
+}
+
+func TestSnapshotPreservesTTL(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	src := New(WithSoftTTL(time.Minute), WithHardTTL(time.Hour))
+	src.now = clock.Now
+	src.Set("short", "a")
+	clock.Advance(30 * time.Minute)
+	src.Set("long", "b")
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	// Простой процесса засчитывается в срок жизни записей.
+	clock.Advance(45 * time.Minute)
+	dst := New()
+	dst.now = clock.Now
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, ok := dst.Get("short"); ok {
+		t.Error("запись с истёкшим жёстким сроком не должна восстанавливаться")
+	}
+	if v, ok := dst.Get("long"); !ok || v != "b" {
+		t.Fatalf("ожидалось 'b', получено %v", v)
+	}
+	clock.Advance(15 * time.Minute)
+	if _, ok := dst.Get("long"); ok {
+		t.Error("восстановленная запись должна истечь в исходный срок")
+	}
+}
+
+func TestRestoreRejectsGarbage(t *testing.T) {
+	t.Parallel()
+	c := New()
+	if err := c.Restore(bytes.NewReader([]byte("not a snapshot"))); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("ожидалась ErrBadSnapshot, получено %v", err)
+	}
+	data := append(snapshotMagic[:], snapshotVersion+1)
+	if err := c.Restore(bytes.NewReader(data)); !errors.Is(err, ErrSnapshotVersion) {
+		t.Errorf("ожидалась ErrSnapshotVersion, получено %v", err)
+	}
+}
+
+func TestRestoreRejectsCorruptHeader(t *testing.T) {
+	t.Parallel()
+	for _, count := range []int{-1, 1 << 40} {
+		var buf bytes.Buffer
+		buf.Write(snapshotMagic[:])
+		buf.WriteByte(snapshotVersion)
+		if err := gob.NewEncoder(&buf).Encode(snapshotHeader{Count: count}); err != nil {
+			t.Fatal(err)
+		}
+		c := New()
+		if err := c.Restore(&buf); !errors.Is(err, ErrBadSnapshot) {
+			t.Errorf("Count=%d: ожидалась ErrBadSnapshot, получено %v", count, err)
+		}
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cache.snap")
+
+	if err := New().RestoreFile(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("ожидалась os.ErrNotExist для отсутствующего снимка, получено %v", err)
+	}
+
+	src := New()
+	src.Set("key", "value")
+	if err := src.SnapshotFile(path); err != nil {
+		t.Fatalf("SnapshotFile: %v", err)
+	}
+	dst := New()
+	if err := dst.RestoreFile(path); err != nil {
+		t.Fatalf("RestoreFile: %v", err)
+	}
+	if v, ok := dst.Get("key"); !ok || v != "value" {
+		t.Errorf("ожидалось 'value', получено %v", v)
+	}
+
+	matches, _ := filepath.Glob(path + ".tmp*")
+	if len(matches) != 0 {
+		t.Errorf("временные файлы должны удаляться: %v", matches)
+	}
+}
+
+func TestSnapshotEvery(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	c := New()
+	c.Set("key", 1)
+
+	stop := c.SnapshotEvery(10*time.Millisecond, path, func(err error) {
+		t.Errorf("неожиданная ошибка снимка: %v", err)
+	})
+	time.Sleep(30 * time.Millisecond)
+	c.Set("key", 2)
+	stop()
+	stop()
+
+	dst := New()
+	if err := dst.RestoreFile(path); err != nil {
+		t.Fatalf("RestoreFile: %v", err)
+	}
+	if v, _ := dst.Get("key"); v != 2 {
+		t.Errorf("финальный снимок при остановке должен содержать 2, получено %v", v)
+	}
+}
@@ -0,0 +1,94 @@
+package cache
+
+import "sync/atomic"
+
+// Stats — снимок счётчиков кэша на момент вызова Cache.Stats.
+type Stats struct {
+	Hits          uint64 // обращения, нашедшие значение (включая устаревшие)
+	Misses        uint64 // обращения к отсутствующим или просроченным ключам
+	StaleHits     uint64 // попадания в значения с истёкшим мягким сроком
+	Evictions     uint64 // записи, удалённые через Delete
+	Expirations   uint64 // записи, удалённые по жёсткому сроку
+	RefreshErrors uint64 // неудачные фоновые обновления
+	Invalidations uint64 // записи, удалённые по сообщению соседнего экземпляра
+	PublishErrors uint64 // неудачные публикации в шину инвалидации
+	Entries       int    // текущее число записей; просроченные удаляются при Get или очередном Set
+}
+
+// HitRatio возвращает долю попаданий среди всех обращений или 0,
+// если обращений не было.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// counters хранит счётчики кэша. Они обновляются атомарно, чтобы не
+// превращать чтение под RLock в запись под общей блокировкой.
+type counters struct {
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	staleHits     atomic.Uint64
+	evictions     atomic.Uint64
+	expirations   atomic.Uint64
+	refreshErrors atomic.Uint64
+	invalidations atomic.Uint64
+	publishErrors atomic.Uint64
+}
+
+// Stats возвращает текущие значения счётчиков.
+func (c *Cache) Stats() Stats {
+	c.mu.RLock()
+	entries := len(c.data)
+	c.mu.RUnlock()
+	return Stats{
+		Hits:          c.stats.hits.Load(),
+		Misses:        c.stats.misses.Load(),
+		StaleHits:     c.stats.staleHits.Load(),
+		Evictions:     c.stats.evictions.Load(),
+		Expirations:   c.stats.expirations.Load(),
+		RefreshErrors: c.stats.refreshErrors.Load(),
+		Invalidations: c.stats.invalidations.Load(),
+		PublishErrors: c.stats.publishErrors.Load(),
+		Entries:       entries,
+	}
+}
+
+// Hook получает ключ и значение затронутой записи. Хуки вызываются
+// синхронно и вне блокировки кэша, поэтому могут обращаться к нему.
+type Hook func(key string, value interface{})
+
+type hooks struct {
+	onSet    []Hook
+	onEvict  []Hook
+	onExpire []Hook
+}
+
+func (h *hooks) set(key string, value interface{})    { run(h.onSet, key, value) }
+func (h *hooks) evict(key string, value interface{})  { run(h.onEvict, key, value) }
+func (h *hooks) expire(key string, value interface{}) { run(h.onExpire, key, value) }
+
+func run(hs []Hook, key string, value interface{}) {
+	for _, h := range hs {
+		h(key, value)
+	}
+}
+
+// OnSet регистрирует хук, вызываемый после записи значения через Set
+// или фоновое обновление.
+func OnSet(h Hook) Option {
+	return func(c *Cache) { c.hooks.onSet = append(c.hooks.onSet, h) }
+}
+
+// OnEvict регистрирует хук, вызываемый после удаления записи через Delete.
+func OnEvict(h Hook) Option {
+	return func(c *Cache) { c.hooks.onEvict = append(c.hooks.onEvict, h) }
+}
+
+// OnExpire регистрирует хук, вызываемый после удаления записи
+// по истечении жёсткого срока.
+func OnExpire(h Hook) Option {
+	return func(c *Cache) { c.hooks.onExpire = append(c.hooks.onExpire, h) }
+}
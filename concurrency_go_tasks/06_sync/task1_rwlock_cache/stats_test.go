@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheDelete(t *testing.T) {
+	t.Parallel()
+	c := New()
+	c.Set("key", "value")
+	c.Delete("key")
+	c.Delete("missing")
+	if _, ok := c.Get("key"); ok {
+		t.Fatal("ключ должен отсутствовать после Delete")
+	}
+	if s := c.Stats(); s.Evictions != 1 {
+		t.Errorf("удаление отсутствующего ключа не должно считаться вытеснением: ожидалось 1, получено %d", s.Evictions)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	c := New(
+		WithSoftTTL(time.Second),
+		WithHardTTL(time.Minute),
+		WithLoader(func(string) (interface{}, error) { return nil, errors.New("fail") }),
+	)
+	c.now = clock.Now
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("missing")
+	clock.Advance(2 * time.Second)
+	c.Get("b")
+	c.refreshWG.Wait()
+	c.Delete("a")
+	clock.Advance(time.Minute)
+	c.Get("b")
+
+	want := Stats{
+		Hits:          2,
+		Misses:        2,
+		StaleHits:     1,
+		Evictions:     1,
+		Expirations:   1,
+		RefreshErrors: 1,
+		Entries:       0,
+	}
+	if got := c.Stats(); got != want {
+		t.Errorf("ожидалась статистика %+v, получено %+v", want, got)
+	}
+	if r := want.HitRatio(); math.Abs(r-0.5) > 1e-9 {
+		t.Errorf("ожидалась доля попаданий 0.5, получено %v", r)
+	}
+	if r := (Stats{}).HitRatio(); r != 0 {
+		t.Errorf("без обращений доля попаданий должна быть 0, получено %v", r)
+	}
+}
+
+func TestCacheHooks(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	var mu sync.Mutex
+	var events []string
+	record := func(kind string) Hook {
+		return func(key string, value interface{}) {
+			mu.Lock()
+			events = append(events, kind+":"+key)
+			mu.Unlock()
+		}
+	}
+	refreshed := make(chan struct{})
+	var c *Cache
+	c = New(
+		WithSoftTTL(time.Second),
+		WithHardTTL(time.Minute),
+		WithLoader(func(key string) (interface{}, error) { return "fresh", nil }),
+		OnSet(record("set")),
+		OnEvict(record("evict")),
+		OnExpire(record("expire")),
+		OnSet(func(key string, value interface{}) {
+			// Хуки вызываются вне блокировки и могут читать кэш.
+			c.Stats()
+			if value == "fresh" {
+				close(refreshed)
+			}
+		}),
+	)
+	c.now = clock.Now
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Delete("a")
+	clock.Advance(2 * time.Second)
+	c.Get("b")
+	<-refreshed
+	c.refreshWG.Wait()
+	clock.Advance(time.Minute)
+	c.Get("b")
+
+	want := []string{"set:a", "set:b", "evict:a", "set:b", "expire:b"}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != len(want) {
+		t.Fatalf("ожидались события %v, получено %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("ожидались события %v, получено %v", want, events)
+		}
+	}
+}
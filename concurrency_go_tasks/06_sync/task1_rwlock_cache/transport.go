@@ -0,0 +1,355 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+var ErrTransportClosed = errors.New("cache: transport closed") // транспорт уже закрыт
+
+// MemoryHub связывает транспорты внутри одного процесса. Сообщения
+// доставляются синхронно в момент публикации.
+type MemoryHub struct {
+	mu      sync.RWMutex
+	members map[*memoryTransport]struct{}
+}
+
+// NewMemoryHub создаёт пустой хаб.
+func NewMemoryHub() *MemoryHub {
+	return &MemoryHub{members: make(map[*memoryTransport]struct{})}
+}
+
+// Transport создаёт новый транспорт, подключённый к хабу.
+func (h *MemoryHub) Transport() Transport {
+	t := &memoryTransport{hub: h}
+	h.mu.Lock()
+	h.members[t] = struct{}{}
+	h.mu.Unlock()
+	return t
+}
+
+type memoryTransport struct {
+	hub     *MemoryHub
+	mu      sync.RWMutex
+	handler func(Message)
+	closed  bool
+}
+
+func (t *memoryTransport) Publish(m Message) error {
+	t.mu.RLock()
+	closed := t.closed
+	t.mu.RUnlock()
+	if closed {
+		return ErrTransportClosed
+	}
+
+	t.hub.mu.RLock()
+	peers := make([]*memoryTransport, 0, len(t.hub.members))
+	for p := range t.hub.members {
+		if p != t {
+			peers = append(peers, p)
+		}
+	}
+	t.hub.mu.RUnlock()
+
+	for _, p := range peers {
+		p.deliver(m)
+	}
+	return nil
+}
+
+func (t *memoryTransport) deliver(m Message) {
+	t.mu.RLock()
+	h := t.handler
+	t.mu.RUnlock()
+	if h != nil {
+		h(m)
+	}
+}
+
+func (t *memoryTransport) Subscribe(h func(Message)) {
+	t.mu.Lock()
+	t.handler = h
+	t.mu.Unlock()
+}
+
+func (t *memoryTransport) Close() error {
+	t.mu.Lock()
+	t.closed = true
+	t.handler = nil
+	t.mu.Unlock()
+	t.hub.mu.Lock()
+	delete(t.hub.members, t)
+	t.hub.mu.Unlock()
+	return nil
+}
+
+// dialTimeout и writeTimeout ограничивают ожидание недоступного соседа
+// фоновой горутиной отправки.
+const (
+	dialTimeout  = time.Second
+	writeTimeout = time.Second
+)
+
+// peerQueueSize ограничивает число сообщений, ожидающих отправки одному
+// соседу.
+const peerQueueSize = 256
+
+var ErrPeerQueueFull = errors.New("cache: peer queue full") // сосед не успевает принимать сообщения, сообщение отброшено
+
+// SocketTransport рассылает сообщения соседям по TCP или Unix-сокетам.
+// Каждое сообщение кодируется одной строкой JSON. У каждого соседа своя
+// очередь и горутина отправки, поэтому Publish не ждёт сети и медленный
+// или недоступный сосед не задерживает Set и Delete. Соединения
+// устанавливаются при первой отправке и переоткрываются после ошибок.
+type SocketTransport struct {
+	ln     net.Listener
+	ctx    context.Context // отменяется в Close и прерывает подключение к соседям
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	handler func(Message)
+	peers   map[string]*peer
+	inbound map[net.Conn]struct{}
+	closed  bool
+
+	wg sync.WaitGroup
+}
+
+// peer — исходящее соединение с соседом. Сообщения из queue отправляет
+// горутина run; собственная блокировка позволяет закрыть соединение и
+// забрать ошибку отправки, не удерживая t.mu, который нужен читающим
+// горутинам.
+type peer struct {
+	network, addr string
+	queue         chan Message
+
+	mu     sync.Mutex
+	conn   net.Conn
+	enc    *json.Encoder
+	err    error // ошибка фоновой отправки, ещё не возвращённая из Publish
+	closed bool
+}
+
+// ListenSocket начинает принимать сообщения по адресу addr в сети network
+// ("tcp", "unix" и т.п.).
+func ListenSocket(network, addr string) (*SocketTransport, error) {
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &SocketTransport{
+		ln:      ln,
+		ctx:     ctx,
+		cancel:  cancel,
+		peers:   make(map[string]*peer),
+		inbound: make(map[net.Conn]struct{}),
+	}
+	t.wg.Add(1)
+	go t.accept()
+	return t, nil
+}
+
+// Addr возвращает адрес, на котором транспорт принимает сообщения.
+func (t *SocketTransport) Addr() net.Addr {
+	return t.ln.Addr()
+}
+
+// AddPeer добавляет соседа, которому будут отправляться сообщения.
+// После Close вызов ничего не делает.
+func (t *SocketTransport) AddPeer(network, addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := network + "://" + addr
+	if _, ok := t.peers[key]; ok || t.closed {
+		return
+	}
+	p := &peer{network: network, addr: addr, queue: make(chan Message, peerQueueSize)}
+	t.peers[key] = p
+	t.wg.Add(1)
+	go t.runPeer(p)
+}
+
+// Subscribe регистрирует обработчик входящих сообщений.
+func (t *SocketTransport) Subscribe(h func(Message)) {
+	t.mu.Lock()
+	t.handler = h
+	t.mu.Unlock()
+}
+
+// Publish ставит сообщение в очередь каждого соседа и не ждёт отправки.
+// Если очередь соседа заполнена, сообщение для него отбрасывается с
+// ErrPeerQueueFull. Ошибки фоновой отправки возвращаются из следующего
+// вызова Publish. Ошибки отдельных соседей объединяются; остальные соседи
+// сообщение получают.
+func (t *SocketTransport) Publish(m Message) error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return ErrTransportClosed
+	}
+	peers := make([]*peer, 0, len(t.peers))
+	for _, p := range t.peers {
+		peers = append(peers, p)
+	}
+	t.mu.Unlock()
+
+	var errs []error
+	for _, p := range peers {
+		if err := p.takeErr(); err != nil {
+			errs = append(errs, err)
+		}
+		select {
+		case p.queue <- m:
+		default:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrPeerQueueFull, p.addr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runPeer отправляет сообщения из очереди соседа до закрытия транспорта.
+func (t *SocketTransport) runPeer(p *peer) {
+	defer t.wg.Done()
+	for {
+		select {
+		case m := <-p.queue:
+			if err := p.send(t.ctx, m); err != nil {
+				p.mu.Lock()
+				p.err = err
+				p.mu.Unlock()
+			}
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// takeErr возвращает и сбрасывает ошибку последней фоновой отправки.
+func (p *peer) takeErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err := p.err
+	p.err = nil
+	return err
+}
+
+// send отправляет сообщение, при необходимости переподключаясь.
+// Подключение прерывается отменой ctx.
+func (p *peer) send(ctx context.Context, m Message) error {
+	p.mu.Lock()
+	conn, enc := p.conn, p.enc
+	p.mu.Unlock()
+	if conn == nil {
+		d := net.Dialer{Timeout: dialTimeout}
+		c, err := d.DialContext(ctx, p.network, p.addr)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			c.Close()
+			return ErrTransportClosed
+		}
+		p.conn, p.enc = c, json.NewEncoder(c)
+		conn, enc = p.conn, p.enc
+		p.mu.Unlock()
+	}
+	err := conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err == nil {
+		err = enc.Encode(m)
+	}
+	if err != nil {
+		p.mu.Lock()
+		conn.Close()
+		if p.conn == conn {
+			p.conn, p.enc = nil, nil
+		}
+		p.mu.Unlock()
+	}
+	return err
+}
+
+// close закрывает соединение, прерывая идущую запись.
+func (p *peer) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.enc = nil, nil
+	}
+}
+
+func (t *SocketTransport) accept() {
+	defer t.wg.Done()
+	for {
+		conn, err := t.ln.Accept()
+		if err != nil {
+			return
+		}
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			conn.Close()
+			return
+		}
+		t.inbound[conn] = struct{}{}
+		t.wg.Add(1)
+		t.mu.Unlock()
+		go t.read(conn)
+	}
+}
+
+func (t *SocketTransport) read(conn net.Conn) {
+	defer t.wg.Done()
+	defer func() {
+		conn.Close()
+		t.mu.Lock()
+		delete(t.inbound, conn)
+		t.mu.Unlock()
+	}()
+	dec := json.NewDecoder(conn)
+	for {
+		var m Message
+		if err := dec.Decode(&m); err != nil {
+			return
+		}
+		t.mu.Lock()
+		h := t.handler
+		t.mu.Unlock()
+		if h != nil {
+			h(m)
+		}
+	}
+}
+
+// Close прекращает приём сообщений, закрывает все соединения и дожидается
+// завершения фоновых горутин.
+func (t *SocketTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.cancel()
+	err := t.ln.Close()
+	for conn := range t.inbound {
+		conn.Close()
+	}
+	peers := t.peers
+	t.mu.Unlock()
+	for _, p := range peers {
+		p.close()
+	}
+	t.wg.Wait()
+	return err
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitMissing ждёт, пока ключ не исчезнет из кэша после асинхронной доставки.
+func waitMissing(t *testing.T, c *Cache, key string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := c.Get(key); !ok {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("ключ %q не был инвалидирован за отведённое время", key)
+}
+
+func listenPair(t *testing.T, network, addrA, addrB string) (*SocketTransport, *SocketTransport) {
+	t.Helper()
+	a, err := ListenSocket(network, addrA)
+	if err != nil {
+		t.Fatalf("ListenSocket: %v", err)
+	}
+	t.Cleanup(func() { a.Close() })
+	b, err := ListenSocket(network, addrB)
+	if err != nil {
+		t.Fatalf("ListenSocket: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	a.AddPeer(network, b.Addr().String())
+	b.AddPeer(network, a.Addr().String())
+	return a, b
+}
+
+func testSocketInvalidation(t *testing.T, ta, tb *SocketTransport) {
+	a := New(WithInvalidation(ta))
+	b := New(WithInvalidation(tb))
+
+	b.Set("key", "b")
+	a.Set("key", "a")
+	waitMissing(t, b, "key")
+
+	a.Set("other", 1)
+	b.Delete("other")
+	waitMissing(t, a, "other")
+}
+
+func TestSocketTransportTCP(t *testing.T) {
+	t.Parallel()
+	a, b := listenPair(t, "tcp", "127.0.0.1:0", "127.0.0.1:0")
+	testSocketInvalidation(t, a, b)
+}
+
+func TestSocketTransportUnix(t *testing.T) {
+	t.Parallel()
+	// Путь к Unix-сокету ограничен по длине, поэтому каталог берём покороче.
+	dir, err := os.MkdirTemp("", "inv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	a, b := listenPair(t, "unix", filepath.Join(dir, "a.sock"), filepath.Join(dir, "b.sock"))
+	testSocketInvalidation(t, a, b)
+}
+
+func TestSocketTransportReconnect(t *testing.T) {
+	t.Parallel()
+	a, err := ListenSocket("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := ListenSocket("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := b.Addr().String()
+	a.AddPeer("tcp", addr)
+
+	received := make(chan Message, 1)
+	b.Subscribe(func(m Message) { received <- m })
+	if err := a.Publish(Message{Key: "first"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	<-received
+	b.Close()
+
+	// Отправка асинхронная, поэтому ошибка записи недоступному соседу
+	// возвращается одним из следующих вызовов Publish.
+	var failed bool
+	for i := 0; i < 50 && !failed; i++ {
+		failed = a.Publish(Message{Key: "lost"}) != nil
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !failed {
+		t.Fatal("ожидалась ошибка публикации недоступному соседу")
+	}
+
+	b, err = ListenSocket("tcp", addr)
+	if err != nil {
+		t.Skipf("не удалось повторно занять адрес %s: %v", addr, err)
+	}
+	defer b.Close()
+	received = make(chan Message, 16)
+	b.Subscribe(func(m Message) { received <- m })
+	// Сообщения, отправленные до перезапуска соседа, могли потеряться, а
+	// Publish — вернуть их ошибки; важно, что отправка восстанавливается.
+	deadline := time.After(2 * time.Second)
+	for {
+		a.Publish(Message{Key: "again"})
+		select {
+		case m := <-received:
+			if m.Key != "again" {
+				t.Errorf("ожидался ключ 'again', получено %q", m.Key)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("сообщение после переподключения не доставлено")
+		}
+	}
+}
+
+func TestSocketTransportUnreachablePeerDoesNotBlock(t *testing.T) {
+	t.Parallel()
+	tr, err := ListenSocket("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Адрес из TEST-NET-1 (RFC 5737): подключение либо зависает до
+	// dialTimeout, либо сразу завершается ошибкой.
+	tr.AddPeer("tcp", "192.0.2.1:9")
+	c := New(WithInvalidation(tr))
+
+	start := time.Now()
+	for i := 0; i < 2*peerQueueSize; i++ {
+		c.Set("key", i)
+		c.Delete("key")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("недоступный сосед не должен задерживать Set и Delete, заняло %s", elapsed)
+	}
+
+	start = time.Now()
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Close должен прерывать подключение к соседу, занял %s", elapsed)
+	}
+}
+
+func TestSocketTransportClosed(t *testing.T) {
+	t.Parallel()
+	tr, err := ListenSocket("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("повторный Close должен быть безопасен: %v", err)
+	}
+	if err := tr.Publish(Message{}); !errors.Is(err, ErrTransportClosed) {
+		t.Errorf("ожидалась ErrTransportClosed, получено %v", err)
+	}
+}
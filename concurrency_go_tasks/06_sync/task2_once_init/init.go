@@ -1,19 +1,75 @@
 package initonce
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
+// initialized читается без once, в том числе пока Init ещё выполняется
+// в другой горутине, поэтому флаг атомарный.
 var (
 	once        sync.Once
-	initialized bool
+	initialized atomic.Bool
 )
 
+// progress хранит ход инициализации для InitStatus.
+var progress struct {
+	mu       sync.Mutex
+	running  bool
+	started  time.Time
+	finished time.Time
+}
+
 // Init выполняет однократную инициализацию ресурса.
 func Init() {
-	// TODO: инициализировать ресурс через sync.Once
+	once.Do(func() {
+		progress.mu.Lock()
+		progress.running, progress.started = true, time.Now()
+		progress.mu.Unlock()
+
+		initialized.Store(true)
+
+		progress.mu.Lock()
+		progress.running, progress.finished = false, time.Now()
+		progress.mu.Unlock()
+	})
+}
+
+// InitContext выполняет Init, но перестаёт ждать, если ctx завершается
+// раньше. Инициализация при этом продолжается и завершится в фоне; её ход
+// показывает InitStatus. Для ресурсов с ошибками и долгой инициализацией используйте Lazy.
+func InitContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		Init()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// InitStatus возвращает стадию инициализации ресурса и её длительность,
+// как Lazy.Status. Инициализация ресурса не может завершиться ошибкой,
+// поэтому стадии Failed не бывает.
+func InitStatus() Status {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+	switch {
+	case progress.running:
+		return Status{State: Running, Elapsed: time.Since(progress.started)}
+	case initialized.Load():
+		return Status{State: Done, Elapsed: progress.finished.Sub(progress.started)}
+	}
+	return Status{State: NotStarted}
 }
 
 // Initialized возвращает, был ли инициализирован ресурс.
 func Initialized() bool {
-	// TODO: вернуть признак инициализации
-	return false
+	return initialized.Load()
 }
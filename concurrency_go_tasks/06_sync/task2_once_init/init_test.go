@@ -1,6 +1,7 @@
 package initonce
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -35,7 +36,7 @@ func TestInitializedMultiple(t *testing.T) {
 func TestInitOnceSingleExecution(t *testing.T) {
 	var callCount int32
 
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	var wg sync.WaitGroup
@@ -62,7 +63,7 @@ func TestInitOnceSingleExecution(t *testing.T) {
 }
 
 func TestInitOnceRaceCondition(t *testing.T) {
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	const numGoroutines = 100
@@ -84,7 +85,7 @@ func TestInitOnceRaceCondition(t *testing.T) {
 }
 
 func TestInitializedState(t *testing.T) {
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	if Initialized() {
@@ -99,7 +100,7 @@ func TestInitializedState(t *testing.T) {
 }
 
 func TestInitOnceMultipleCalls(t *testing.T) {
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	Init()
@@ -112,7 +113,7 @@ func TestInitOnceMultipleCalls(t *testing.T) {
 }
 
 func TestInitOnceConcurrentCalls(t *testing.T) {
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	const numCalls = 500
@@ -134,7 +135,7 @@ func TestInitOnceConcurrentCalls(t *testing.T) {
 }
 
 func TestInitOnceNoRace(t *testing.T) {
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	var wg sync.WaitGroup
@@ -159,7 +160,7 @@ func TestInitOnceNoRace(t *testing.T) {
 }
 
 func TestInitOnceTiming(t *testing.T) {
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	start := time.Now()
@@ -187,7 +188,7 @@ func TestInitOnceTiming(t *testing.T) {
 }
 
 func BenchmarkInitOnce(b *testing.B) {
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	b.ResetTimer()
@@ -197,7 +198,7 @@ func BenchmarkInitOnce(b *testing.B) {
 }
 
 func BenchmarkInitOnceConcurrent(b *testing.B) {
-	initialized = false
+	initialized.Store(false)
 	once = sync.Once{}
 
 	b.ResetTimer()
@@ -207,3 +208,54 @@ func BenchmarkInitOnceConcurrent(b *testing.B) {
 		}
 	})
 }
+
+func TestInitializedConcurrentWithInit(t *testing.T) {
+	initialized.Store(false)
+	once = sync.Once{}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for !Initialized() {
+		}
+	}()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		Init()
+	}()
+	defer wg.Wait() // следующие тесты сбрасывают once
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Initialized() не увидел завершения Init() из другой горутины")
+	}
+}
+
+func TestInitContext(t *testing.T) {
+	initialized.Store(false)
+	once = sync.Once{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := InitContext(ctx); err != nil {
+		t.Fatalf("InitContext вернул ошибку: %v", err)
+	}
+	if !Initialized() {
+		t.Fatal("ресурс не был инициализирован после InitContext")
+	}
+}
+
+func TestInitStatus(t *testing.T) {
+	initialized.Store(false)
+	once = sync.Once{}
+
+	if s := InitStatus(); s.State != NotStarted || s.Elapsed != 0 {
+		t.Fatalf("до Init ожидалось NotStarted, получено %+v", s)
+	}
+	Init()
+	if s := InitStatus(); s.State != Done || s.Elapsed < 0 || s.Err != nil {
+		t.Fatalf("после Init ожидалось Done, получено %+v", s)
+	}
+}
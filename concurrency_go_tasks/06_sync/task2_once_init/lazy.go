@@ -0,0 +1,243 @@
+package initonce
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Option настраивает Lazy при создании.
+type Option func(*options)
+
+type options struct {
+	minBackoff      time.Duration
+	maxBackoff      time.Duration
+	initTimeout     time.Duration
+	cancelAbandoned bool
+}
+
+// WithBackoff задаёт паузу между повторными попытками после неудачной
+// инициализации: первая пауза равна minDelay, каждая следующая вдвое длиннее,
+// но не больше maxDelay. Пока пауза не истекла, Get сразу возвращает последнюю
+// ошибку. Без этой опции повтор выполняется при следующем же вызове.
+func WithBackoff(minDelay, maxDelay time.Duration) Option {
+	return func(o *options) {
+		o.minBackoff = minDelay
+		o.maxBackoff = maxDelay
+	}
+}
+
+// WithInitTimeout ограничивает одну попытку инициализации: контекст,
+// переданный в init, истекает через d после её начала.
+func WithInitTimeout(d time.Duration) Option {
+	return func(o *options) { o.initTimeout = d }
+}
+
+// WithCancelAbandoned отменяет контекст попытки, когда все ожидающие её
+// вызовы Get вернулись по своему контексту. По умолчанию попытка
+// продолжается и её результат получат следующие вызовы; эта опция
+// нужна, когда инициализация без ожидающих бессмысленна, например если
+// её результат некому закрыть.
+func WithCancelAbandoned() Option {
+	return func(o *options) { o.cancelAbandoned = true }
+}
+
+// State — стадия инициализации Lazy.
+type State int
+
+const (
+	NotStarted State = iota // инициализация ещё не запускалась или был Reset
+	Running                 // попытка выполняется
+	Done                    // значение получено
+	Failed                  // последняя попытка завершилась ошибкой
+)
+
+func (s State) String() string {
+	switch s {
+	case NotStarted:
+		return "NotStarted"
+	case Running:
+		return "Running"
+	case Done:
+		return "Done"
+	case Failed:
+		return "Failed"
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Status описывает ход инициализации на момент вызова Lazy.Status.
+type Status struct {
+	State   State
+	Elapsed time.Duration // длительность текущей или последней попытки
+	Err     error         // ошибка последней попытки для Failed
+}
+
+// Lazy лениво создаёт значение типа T при первом вызове Get. Успешный
+// результат запоминается, а неудачная инициализация повторяется при
+// следующем вызове. Одновременные вызовы Get дожидаются одной попытки.
+type Lazy[T any] struct {
+	init func(ctx context.Context) (T, error)
+	opts options
+	now  func() time.Time
+
+	mu       sync.Mutex
+	done     bool
+	value    T
+	call     *call[T]
+	err      error
+	failures int
+	retryAt  time.Time
+	gen      uint64
+	started  time.Time
+	finished time.Time
+}
+
+// call — выполняющаяся попытка инициализации. Результат доступен после
+// закрытия done.
+type call[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+
+	waiters int                // вызовы Get, ждущие попытку; под Lazy.mu
+	cancel  context.CancelFunc // отменяет попытку при WithCancelAbandoned
+}
+
+// NewLazy создаёт Lazy, который получает значение через init.
+func NewLazy[T any](init func(ctx context.Context) (T, error), opts ...Option) *Lazy[T] {
+	l := &Lazy[T]{init: init, now: time.Now}
+	for _, opt := range opts {
+		opt(&l.opts)
+	}
+	return l
+}
+
+// Get возвращает значение, при необходимости инициализируя его.
+// Инициализация выполняется в отдельной горутине с контекстом, который
+// наследует значения ctx, но не его отмену и срок. Если ctx истекает
+// раньше, Get возвращает ошибку контекста, а попытка продолжается, и её
+// результат получат следующие вызовы (см. также WithCancelAbandoned).
+func (l *Lazy[T]) Get(ctx context.Context) (T, error) {
+	l.mu.Lock()
+	if l.done {
+		v := l.value
+		l.mu.Unlock()
+		return v, nil
+	}
+	c := l.call
+	if c == nil {
+		if l.err != nil && l.now().Before(l.retryAt) {
+			err := l.err
+			l.mu.Unlock()
+			var zero T
+			return zero, err
+		}
+		c = &call[T]{done: make(chan struct{})}
+		runCtx := context.WithoutCancel(ctx)
+		if l.opts.cancelAbandoned {
+			runCtx, c.cancel = context.WithCancel(runCtx)
+		}
+		l.call = c
+		l.started = l.now()
+		go l.run(runCtx, c, l.gen)
+	}
+	c.waiters++
+	l.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		l.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 && c.cancel != nil {
+			c.cancel()
+		}
+		l.mu.Unlock()
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// run выполняет одну попытку инициализации.
+func (l *Lazy[T]) run(ctx context.Context, c *call[T], gen uint64) {
+	if l.opts.initTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.opts.initTimeout)
+		defer cancel()
+	}
+	c.value, c.err = l.init(ctx)
+	if c.cancel != nil {
+		c.cancel()
+	}
+	l.finish(c, gen)
+}
+
+// finish сохраняет результат попытки, если с её начала не было Reset.
+func (l *Lazy[T]) finish(c *call[T], gen uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer close(c.done)
+	if l.gen != gen {
+		return
+	}
+	l.call = nil
+	l.finished = l.now()
+	if c.err == nil {
+		l.done = true
+		l.value = c.value
+		l.err = nil
+		l.failures = 0
+		return
+	}
+	l.err = c.err
+	l.failures++
+	l.retryAt = l.now().Add(l.backoff())
+}
+
+// backoff возвращает паузу перед следующей попыткой. Вызывается под l.mu.
+func (l *Lazy[T]) backoff() time.Duration {
+	d := l.opts.minBackoff
+	for i := 1; i < l.failures && d < l.opts.maxBackoff; i++ {
+		d *= 2
+	}
+	if l.opts.maxBackoff > 0 && d > l.opts.maxBackoff {
+		d = l.opts.maxBackoff
+	}
+	return d
+}
+
+// Reset забывает результат, чтобы следующий Get выполнил инициализацию
+// заново. Результат попытки, идущей во время Reset, не сохраняется, но
+// её ожидающие его получат. Предназначен в первую очередь для тестов.
+func (l *Lazy[T]) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var zero T
+	l.done = false
+	l.value = zero
+	l.call = nil
+	l.err = nil
+	l.failures = 0
+	l.retryAt = time.Time{}
+	l.started = time.Time{}
+	l.finished = time.Time{}
+	l.gen++
+}
+
+// Status возвращает текущую стадию инициализации и её длительность.
+func (l *Lazy[T]) Status() Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case l.call != nil:
+		return Status{State: Running, Elapsed: l.now().Sub(l.started)}
+	case l.done:
+		return Status{State: Done, Elapsed: l.finished.Sub(l.started)}
+	case l.err != nil:
+		return Status{State: Failed, Elapsed: l.finished.Sub(l.started), Err: l.err}
+	}
+	return Status{State: NotStarted}
+}
@@ -0,0 +1,318 @@
+package initonce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLazyInitializesOnce(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	l := NewLazy(func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.Get(context.Background())
+			if err != nil || v != 42 {
+				t.Errorf("ожидалось 42 без ошибки, получено %d, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("инициализация должна выполняться один раз, выполнено %d", calls)
+	}
+}
+
+func TestLazyRetriesAfterFailure(t *testing.T) {
+	t.Parallel()
+	errBoom := errors.New("boom")
+	var calls int32
+	l := NewLazy(func(ctx context.Context) (string, error) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return "", errBoom
+		}
+		return "ready", nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := l.Get(context.Background()); !errors.Is(err, errBoom) {
+			t.Fatalf("попытка %d: ожидалась errBoom, получено %v", i+1, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := l.Get(context.Background()); err != nil || v != "ready" {
+			t.Fatalf("ожидалось 'ready', получено %q, %v", v, err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("успешный результат должен кэшироваться: ожидалось 3 вызова, получено %d", calls)
+	}
+}
+
+func TestLazyBackoff(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var calls int32
+	l := NewLazy(func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, errors.New("unavailable")
+	}, WithBackoff(time.Second, 3*time.Second))
+	l.now = func() time.Time { return now }
+
+	get := func() {
+		if _, err := l.Get(context.Background()); err == nil {
+			t.Fatal("ожидалась ошибка")
+		}
+	}
+	expect := func(want int32) {
+		t.Helper()
+		if got := atomic.LoadInt32(&calls); got != want {
+			t.Fatalf("ожидалось %d попыток, получено %d", want, got)
+		}
+	}
+
+	get()
+	get()
+	expect(1)
+	now = now.Add(time.Second)
+	get()
+	expect(2)
+	now = now.Add(1999 * time.Millisecond)
+	get()
+	expect(2)
+	now = now.Add(time.Millisecond)
+	get()
+	expect(3)
+	// Пауза удваивается до максимума в 3 секунды.
+	now = now.Add(3 * time.Second)
+	get()
+	expect(4)
+}
+
+func TestLazyWaiterContext(t *testing.T) {
+	t.Parallel()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	l := NewLazy(func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+
+	go l.Get(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ожидающий вызов должен завершаться по своему контексту, получено %v", err)
+	}
+	close(release)
+	if v, err := l.Get(context.Background()); err != nil || v != 1 {
+		t.Errorf("ожидалось 1, получено %d, %v", v, err)
+	}
+}
+
+func TestLazyReset(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	l := NewLazy(func(ctx context.Context) (int32, error) {
+		return atomic.AddInt32(&calls, 1), nil
+	})
+
+	if v, _ := l.Get(context.Background()); v != 1 {
+		t.Fatalf("ожидалось 1, получено %d", v)
+	}
+	l.Reset()
+	if v, _ := l.Get(context.Background()); v != 2 {
+		t.Fatalf("после Reset инициализация должна выполниться заново: ожидалось 2, получено %d", v)
+	}
+}
+
+func TestLazyResetDuringInit(t *testing.T) {
+	t.Parallel()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls int32
+	l := NewLazy(func(ctx context.Context) (int32, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			close(started)
+			<-release
+		}
+		return n, nil
+	})
+
+	result := make(chan int32)
+	go func() {
+		v, _ := l.Get(context.Background())
+		result <- v
+	}()
+	<-started
+	l.Reset()
+	close(release)
+	if v := <-result; v != 1 {
+		t.Fatalf("вызвавший получает результат своей попытки: ожидалось 1, получено %d", v)
+	}
+	if v, _ := l.Get(context.Background()); v != 2 {
+		t.Errorf("результат попытки, прерванной Reset, не должен сохраняться: ожидалось 2, получено %d", v)
+	}
+}
+
+func TestLazyInitContinuesAfterWaiterTimeout(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	var initCtxErr atomic.Value
+	l := NewLazy(func(ctx context.Context) (string, error) {
+		<-release
+		initCtxErr.Store(fmt.Sprint(ctx.Err()))
+		return "slow", nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("первый вызов должен завершиться по своему сроку, получено %v", err)
+	}
+	if s := l.Status(); s.State != Running {
+		t.Fatalf("инициализация должна продолжаться после таймаута вызывающего, состояние %v", s.State)
+	}
+	close(release)
+	if v, err := l.Get(context.Background()); err != nil || v != "slow" {
+		t.Fatalf("ожидалось 'slow', получено %q, %v", v, err)
+	}
+	if got := initCtxErr.Load(); got != "<nil>" {
+		t.Errorf("отмена вызывающего не должна отменять контекст init, получено %v", got)
+	}
+}
+
+func TestLazyCancelAbandoned(t *testing.T) {
+	t.Parallel()
+	initErr := make(chan error, 1)
+	l := NewLazy(func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		initErr <- ctx.Err()
+		return 0, ctx.Err()
+	}, WithCancelAbandoned())
+
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	long, cancelLong := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancelLong()
+	var wg sync.WaitGroup
+	for _, ctx := range []context.Context{short, long} {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			l.Get(ctx)
+		}(ctx)
+	}
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case err := <-initErr:
+		t.Fatalf("попытка не должна отменяться, пока её ждёт хотя бы один вызов: %v", err)
+	default:
+	}
+	wg.Wait()
+	select {
+	case err := <-initErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ожидалась отмена попытки, получено %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("попытка без ожидающих должна отменяться")
+	}
+}
+
+func TestLazyInitTimeout(t *testing.T) {
+	t.Parallel()
+	l := NewLazy(func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, WithInitTimeout(10*time.Millisecond))
+
+	if _, err := l.Get(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("попытка должна ограничиваться WithInitTimeout, получено %v", err)
+	}
+	if s := l.Status(); s.State != Failed || !errors.Is(s.Err, context.DeadlineExceeded) {
+		t.Errorf("ожидалось состояние Failed с DeadlineExceeded, получено %+v", s)
+	}
+}
+
+func TestLazyStatus(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		now = now.Add(d)
+		mu.Unlock()
+	}
+
+	started := make(chan struct{})
+	release := make(chan error)
+	l := NewLazy(func(ctx context.Context) (int, error) {
+		started <- struct{}{}
+		return 1, <-release
+	})
+	l.now = clock
+
+	if s := l.Status(); s.State != NotStarted || s.Elapsed != 0 {
+		t.Fatalf("ожидалось NotStarted, получено %+v", s)
+	}
+
+	result := make(chan error)
+	go func() {
+		_, err := l.Get(context.Background())
+		result <- err
+	}()
+	<-started
+	advance(3 * time.Second)
+	if s := l.Status(); s.State != Running || s.Elapsed != 3*time.Second {
+		t.Fatalf("ожидалось Running 3s, получено %+v", s)
+	}
+	errBoom := errors.New("boom")
+	release <- errBoom
+	<-result
+	if s := l.Status(); s.State != Failed || s.Elapsed != 3*time.Second || s.Err != errBoom {
+		t.Fatalf("ожидалось Failed 3s с ошибкой, получено %+v", s)
+	}
+
+	go func() {
+		_, err := l.Get(context.Background())
+		result <- err
+	}()
+	<-started
+	advance(time.Second)
+	release <- nil
+	<-result
+	if s := l.Status(); s.State != Done || s.Elapsed != time.Second || s.Err != nil {
+		t.Fatalf("ожидалось Done 1s, получено %+v", s)
+	}
+
+	l.Reset()
+	if s := l.Status(); s.State != NotStarted {
+		t.Errorf("после Reset ожидалось NotStarted, получено %v", s.State)
+	}
+	if got := Failed.String(); got != "Failed" {
+		t.Errorf("ожидалось 'Failed', получено %q", got)
+	}
+}
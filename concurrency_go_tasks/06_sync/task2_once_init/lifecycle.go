@@ -0,0 +1,281 @@
+package initonce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrDuplicate = errors.New("initonce: component already registered")      // имя компонента уже занято
+var ErrUnknownDependency = errors.New("initonce: unknown dependency")        // зависимость не зарегистрирована
+var ErrCycle = errors.New("initonce: dependency cycle")                      // зависимости образуют цикл
+var ErrDependencyFailed = errors.New("initonce: dependency failed to start") // зависимость не инициализировалась
+
+// Component описывает часть приложения с жизненным циклом: клиента БД,
+// кэша, очереди и т.п. Init и Close могут быть nil.
+type Component struct {
+	Name  string
+	Deps  []string
+	Init  func(ctx context.Context) error
+	Close func(ctx context.Context) error
+}
+
+// Registry запускает компоненты в порядке зависимостей и останавливает
+// их в обратном порядке. Независимые компоненты обрабатываются
+// параллельно, каждый инициализируется не более одного раза.
+type Registry struct {
+	mu    sync.Mutex
+	nodes map[string]*node
+}
+
+type node struct {
+	Component
+	lazy    *Lazy[struct{}]
+	started bool
+}
+
+// NewRegistry создаёт пустой реестр.
+func NewRegistry() *Registry {
+	return &Registry{nodes: make(map[string]*node)}
+}
+
+// Register добавляет компонент. Зависимости могут регистрироваться позже,
+// они проверяются при Start.
+func (r *Registry) Register(c Component) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.nodes[c.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicate, c.Name)
+	}
+	n := &node{Component: c}
+	// Когда Start перестаёт ждать, Init компонента отменяется: иначе он
+	// мог бы запуститься без ведома вызывающего и остаться незакрытым.
+	n.lazy = NewLazy(func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, r.initNode(ctx, n)
+	}, WithCancelAbandoned())
+	r.nodes[c.Name] = n
+	return nil
+}
+
+// initNode инициализирует сам компонент. Зависимости к этому моменту
+// уже запущены. Если Init завершился успешно, но Start к этому времени
+// уже отменён, компонент сразу закрывается и не считается запущенным.
+func (r *Registry) initNode(ctx context.Context, n *node) error {
+	if n.Init != nil {
+		if err := n.Init(ctx); err != nil {
+			return fmt.Errorf("initonce: init %s: %w", n.Name, err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		if n.Close != nil {
+			n.Close(context.WithoutCancel(ctx))
+		}
+		return fmt.Errorf("initonce: init %s: %w", n.Name, err)
+	}
+	r.mu.Lock()
+	n.started = true
+	r.mu.Unlock()
+	return nil
+}
+
+// Start инициализирует все зарегистрированные компоненты: каждый — после
+// своих зависимостей, независимые — одновременно. Уже запущенные
+// компоненты пропускаются, поэтому после ошибки Start можно вызвать снова.
+// За один вызов каждый компонент делает не больше одной попытки. Ошибки
+// всех компонентов объединяются; компоненты, чья зависимость не
+// запустилась, возвращают ErrDependencyFailed. Отмена ctx отменяет и
+// контекст, переданный в Init компонентов.
+func (r *Registry) Start(ctx context.Context) error {
+	r.mu.Lock()
+	if err := r.validate(); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	// attempts хранит результат попытки каждого компонента в этом вызове:
+	// зависимые ждут его, а не запускают инициализацию повторно.
+	type attempt struct {
+		done chan struct{}
+		err  error
+	}
+	attempts := make(map[string]*attempt, len(r.nodes))
+	nodes := make([]*node, 0, len(r.nodes))
+	for name, n := range r.nodes {
+		attempts[name] = &attempt{done: make(chan struct{})}
+		nodes = append(nodes, n)
+	}
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			a := attempts[n.Name]
+			defer close(a.done)
+			for _, dep := range n.Deps {
+				d := attempts[dep]
+				<-d.done
+				if d.err != nil {
+					a.err = fmt.Errorf("%s: %w: %s", n.Name, ErrDependencyFailed, dep)
+					return
+				}
+			}
+			_, a.err = n.lazy.Get(ctx)
+		}(n)
+	}
+	wg.Wait()
+
+	errs := make([]error, 0, len(nodes))
+	for _, n := range nodes {
+		errs = append(errs, attempts[n.Name].err)
+	}
+	return errors.Join(errs...)
+}
+
+// validate проверяет, что все зависимости известны и не образуют цикла.
+// Вызывается под r.mu.
+func (r *Registry) validate() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(r.nodes))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			start := 0
+			for path[start] != name {
+				start++
+			}
+			return fmt.Errorf("%w: %s", ErrCycle, strings.Join(append(path[start:], name), " -> "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range r.nodes[name].Deps {
+			if _, ok := r.nodes[dep]; !ok {
+				return fmt.Errorf("%w: %s requires %s", ErrUnknownDependency, name, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	// Обход в алфавитном порядке делает сообщения об ошибках стабильными.
+	names := make([]string, 0, len(r.nodes))
+	for name := range r.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown закрывает запущенные компоненты в порядке, обратном
+// инициализации: компонент закрывается только после всех запущенных
+// компонентов, которые от него зависят. Если ctx истекает раньше,
+// незакрытые компоненты больше не закрываются и Shutdown возвращает
+// ошибку с их перечнем. Закрытые компоненты можно запустить снова.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	var started []*node
+	for _, n := range r.nodes {
+		if n.started {
+			started = append(started, n)
+		}
+	}
+	dependents := make(map[*node][]*node)
+	for _, n := range started {
+		for _, name := range n.Deps {
+			if d := r.nodes[name]; d.started {
+				dependents[d] = append(dependents[d], n)
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	done := make(map[*node]chan struct{}, len(started))
+	for _, n := range started {
+		done[n] = make(chan struct{})
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	pending := make(map[string]struct{}, len(started))
+	for _, n := range started {
+		pending[n.Name] = struct{}{}
+	}
+	fail := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	for _, n := range started {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			defer close(done[n])
+			for _, d := range dependents[n] {
+				select {
+				case <-done[d]:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if n.Close != nil {
+				if err := n.Close(ctx); err != nil {
+					fail(fmt.Errorf("initonce: close %s: %w", n.Name, err))
+				}
+			}
+			r.mu.Lock()
+			n.started = false
+			r.mu.Unlock()
+			n.lazy.Reset()
+			mu.Lock()
+			delete(pending, n.Name)
+			mu.Unlock()
+		}(n)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pending) > 0 {
+		names := make([]string, 0, len(pending))
+		for name := range pending {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		errs = append(errs, fmt.Errorf("initonce: shutdown: %w; not closed: %s", ctx.Err(), strings.Join(names, ", ")))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,329 @@
+package initonce
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// eventLog записывает порядок вызовов Init и Close.
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(e string) {
+	l.mu.Lock()
+	l.events = append(l.events, e)
+	l.mu.Unlock()
+}
+
+func (l *eventLog) index(e string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, v := range l.events {
+		if v == e {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *eventLog) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.events)
+}
+
+func logged(log *eventLog, name string, deps ...string) Component {
+	return Component{
+		Name: name,
+		Deps: deps,
+		Init: func(ctx context.Context) error {
+			log.add("init " + name)
+			return nil
+		},
+		Close: func(ctx context.Context) error {
+			log.add("close " + name)
+			return nil
+		},
+	}
+}
+
+func mustRegister(t *testing.T, r *Registry, cs ...Component) {
+	t.Helper()
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			t.Fatalf("Register(%s): %v", c.Name, err)
+		}
+	}
+}
+
+func TestRegistryOrder(t *testing.T) {
+	t.Parallel()
+	log := &eventLog{}
+	r := NewRegistry()
+	mustRegister(t, r,
+		logged(log, "api", "cache", "queue"),
+		logged(log, "cache", "db"),
+		logged(log, "queue", "db"),
+		logged(log, "db"),
+	)
+
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	before := func(a, b string) {
+		t.Helper()
+		if ia, ib := log.index(a), log.index(b); ia < 0 || ib < 0 || ia > ib {
+			t.Errorf("%q должно произойти раньше %q: %v", a, b, log.events)
+		}
+	}
+	before("init db", "init cache")
+	before("init db", "init queue")
+	before("init cache", "init api")
+	before("init queue", "init api")
+
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("повторный Start: %v", err)
+	}
+	if n := log.count(); n != 4 {
+		t.Errorf("каждый компонент должен инициализироваться один раз, событий: %d", n)
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	before("close api", "close cache")
+	before("close api", "close queue")
+	before("close cache", "close db")
+	before("close queue", "close db")
+}
+
+func TestRegistryParallelInit(t *testing.T) {
+	t.Parallel()
+	// Оба компонента ждут друг друга: тест завершится, только если
+	// независимые компоненты инициализируются одновременно.
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	wait := func(ctx context.Context) error {
+		barrier.Done()
+		barrier.Wait()
+		return nil
+	}
+	r := NewRegistry()
+	mustRegister(t, r,
+		Component{Name: "a", Init: wait},
+		Component{Name: "b", Init: wait},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := r.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+}
+
+func TestRegistryValidation(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	mustRegister(t, r, Component{Name: "a"})
+	if err := r.Register(Component{Name: "a"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("ожидалась ErrDuplicate, получено %v", err)
+	}
+
+	r = NewRegistry()
+	mustRegister(t, r, Component{Name: "a", Deps: []string{"missing"}})
+	if err := r.Start(context.Background()); !errors.Is(err, ErrUnknownDependency) {
+		t.Errorf("ожидалась ErrUnknownDependency, получено %v", err)
+	}
+
+	var inits int32
+	init := func(ctx context.Context) error {
+		atomic.AddInt32(&inits, 1)
+		return nil
+	}
+	r = NewRegistry()
+	mustRegister(t, r,
+		Component{Name: "a", Deps: []string{"b"}, Init: init},
+		Component{Name: "b", Deps: []string{"c"}, Init: init},
+		Component{Name: "c", Deps: []string{"a"}, Init: init},
+	)
+	err := r.Start(context.Background())
+	if !errors.Is(err, ErrCycle) {
+		t.Fatalf("ожидалась ErrCycle, получено %v", err)
+	}
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("сообщение должно содержать цикл, получено %q", err)
+	}
+	if inits != 0 {
+		t.Errorf("при ошибке проверки ничего не должно инициализироваться, вызовов: %d", inits)
+	}
+}
+
+func TestRegistryFailureAndRetry(t *testing.T) {
+	t.Parallel()
+	log := &eventLog{}
+	errDown := errors.New("db down")
+	var attempts int32
+	r := NewRegistry()
+	mustRegister(t, r,
+		Component{
+			Name: "db",
+			Init: func(ctx context.Context) error {
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					return errDown
+				}
+				log.add("init db")
+				return nil
+			},
+			Close: func(ctx context.Context) error {
+				log.add("close db")
+				return nil
+			},
+		},
+		logged(log, "cache", "db"),
+		logged(log, "metrics"),
+	)
+
+	err := r.Start(context.Background())
+	if !errors.Is(err, errDown) || !errors.Is(err, ErrDependencyFailed) {
+		t.Fatalf("ожидались errDown и ErrDependencyFailed, получено %v", err)
+	}
+	if log.index("init cache") >= 0 {
+		t.Fatal("компонент не должен запускаться без своей зависимости")
+	}
+	if log.index("init metrics") < 0 {
+		t.Fatal("независимый компонент должен запуститься несмотря на ошибку")
+	}
+
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("повторный Start должен повторить неудачные компоненты: %v", err)
+	}
+	if log.index("init cache") < 0 {
+		t.Fatal("после повтора зависимый компонент должен запуститься")
+	}
+	if n := strings.Count(strings.Join(log.events, ","), "init metrics"); n != 1 {
+		t.Errorf("запущенный компонент не должен инициализироваться повторно, вызовов: %d", n)
+	}
+}
+
+func TestRegistryStartTimeoutCancelsInit(t *testing.T) {
+	t.Parallel()
+	initErr := make(chan error, 1)
+	r := NewRegistry()
+	mustRegister(t, r, Component{
+		Name: "db",
+		Init: func(ctx context.Context) error {
+			<-ctx.Done()
+			initErr <- ctx.Err()
+			return ctx.Err()
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Start(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ожидалась ошибка срока Start, получено %v", err)
+	}
+	select {
+	case err := <-initErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Init должен увидеть отмену, получено %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Init не получил отмену после таймаута Start")
+	}
+}
+
+func TestRegistryClosesComponentStartedTooLate(t *testing.T) {
+	t.Parallel()
+	log := &eventLog{}
+	r := NewRegistry()
+	mustRegister(t, r, Component{
+		Name: "db",
+		Init: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil // компонент не проверяет контекст
+		},
+		Close: func(ctx context.Context) error {
+			log.add("close db")
+			return nil
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r.Start(ctx)
+	deadline := time.Now().Add(time.Second)
+	for log.index("close db") < 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if log.index("close db") < 0 {
+		t.Fatal("компонент, запустившийся после отмены Start, должен закрываться")
+	}
+	r.mu.Lock()
+	started := r.nodes["db"].started
+	r.mu.Unlock()
+	if started {
+		t.Error("такой компонент не должен считаться запущенным")
+	}
+}
+
+func TestRegistryShutdownTimeout(t *testing.T) {
+	t.Parallel()
+	log := &eventLog{}
+	release := make(chan struct{})
+	defer close(release)
+	r := NewRegistry()
+	mustRegister(t, r,
+		logged(log, "db"),
+		Component{
+			Name: "worker",
+			Deps: []string{"db"},
+			Close: func(ctx context.Context) error {
+				<-release
+				return nil
+			},
+		},
+	)
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := r.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ожидалась ошибка по таймауту, получено %v", err)
+	}
+	if !strings.Contains(err.Error(), "db") || !strings.Contains(err.Error(), "worker") {
+		t.Errorf("ошибка должна перечислять незакрытые компоненты, получено %q", err)
+	}
+	if log.index("close db") >= 0 {
+		t.Error("зависимость не должна закрываться раньше зависящего от неё компонента")
+	}
+}
+
+func TestRegistryRestartAfterShutdown(t *testing.T) {
+	t.Parallel()
+	log := &eventLog{}
+	r := NewRegistry()
+	mustRegister(t, r, logged(log, "db"))
+	for i := 0; i < 2; i++ {
+		if err := r.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		if err := r.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown: %v", err)
+		}
+	}
+	if n := log.count(); n != 4 {
+		t.Errorf("ожидалось 2 цикла запуска и остановки, событий: %d (%v)", n, log.events)
+	}
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown без запущенных компонентов не должен возвращать ошибку: %v", err)
+	}
+}